@@ -0,0 +1,122 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package adder
+
+// Float64Accumulator is ported version of OpenJDK9 DoubleAccumulator.
+//
+// One or more variables, called Cells, together maintain a running float64 value updated using a supplied operator.
+// When updates are contended across routines, the set of variables may grow dynamically to reduce contention.
+// Get (or GetAndReset) returns the current value across the variables maintaining updates.
+//
+// The order of accumulation within or across routines is not guaranteed and cannot be depended upon,
+// so the supplied operator should be associative and commutative (e.g. Float64Max, Float64Min),
+// and side-effect-free.
+//
+// Float64Accumulator is high performance, non-blocking and safe for concurrent use.
+type Float64Accumulator struct {
+	stripedF64
+	op       Float64BinaryOperator
+	identity float64
+}
+
+// NewFloat64Accumulator creates new Float64Accumulator using the given operator and identity value.
+// For example, NewFloat64Accumulator(Float64Max, math.Inf(-1)) tracks maximum value.
+func NewFloat64Accumulator(op Float64BinaryOperator, identity float64) *Float64Accumulator {
+	f := &Float64Accumulator{
+		op:       op,
+		identity: identity,
+	}
+	f.base.store(identity)
+	return f
+}
+
+// Accumulate updates with the given value.
+func (f *Float64Accumulator) Accumulate(x float64) {
+	_as, uncontended := f.cells.Load(), false
+	if _as != nil {
+		uncontended = true
+	} else if b := f.base.load(); !f.casBaseIfChanged(b, f.op.Apply(b, x)) {
+		uncontended = true
+	}
+
+	if uncontended {
+		if _as == nil {
+			f.accumulate(getRandomInt(), x, f.op, true)
+			return
+		}
+
+		as := _as.(cells)
+		m := len(as) - 1
+		if m < 0 {
+			f.accumulate(getRandomInt(), x, f.op, true)
+			return
+		}
+
+		probe := getRandomInt() & m
+		if _a := as[probe].Load(); _a == nil {
+			f.accumulate(probe, x, f.op, uncontended)
+		} else {
+			a := _a.(*cellf64)
+
+			v := a.load()
+			if r := f.op.Apply(v, x); r != v && !a.cas(v, r) {
+				f.accumulate(probe, x, f.op, false)
+			}
+		}
+	}
+}
+
+func (f *Float64Accumulator) casBaseIfChanged(old, new float64) bool {
+	return old == new || f.base.cas(old, new)
+}
+
+// Get returns the current value. The returned value is NOT an
+// atomic snapshot because of concurrent update.
+func (f *Float64Accumulator) Get() float64 {
+	result, _as := f.base.load(), f.cells.Load()
+	if _as != nil {
+		as := _as.(cells)
+		var a interface{}
+		for i := range as {
+			if a = as[i].Load(); a != nil {
+				result = f.op.Apply(result, a.(*cellf64).load())
+			}
+		}
+	}
+	return result
+}
+
+// Reset variables maintaining updates to the identity value. This method may be a useful alternative
+// to creating a new accumulator, but is only effective if there are no concurrent updates.
+func (f *Float64Accumulator) Reset() {
+	f.base.store(f.identity)
+	if _as := f.cells.Load(); _as != nil {
+		cls := make(cells, len(_as.(cells)))
+		for i := range cls {
+			c := &cellf64{}
+			c.store(f.identity)
+			cls[i].Store(c)
+		}
+		f.cells.Store(cls)
+	}
+}
+
+// GetAndReset equivalent in effect to get followed by reset. Like the nature of Get and Reset,
+// this function is only effective if there are no concurrent updates.
+func (f *Float64Accumulator) GetAndReset() (v float64) {
+	v = f.Get()
+	f.Reset()
+	return
+}
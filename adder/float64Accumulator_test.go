@@ -0,0 +1,76 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package adder
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestFloat64AccumulatorNotRaceMax(t *testing.T) {
+	acc := NewFloat64Accumulator(Float64Max, math.Inf(-1))
+	if !math.IsInf(acc.Get(), -1) {
+		t.Errorf("Accumulator logic is wrong")
+	}
+
+	for i := 0; i < delta; i++ {
+		acc.Accumulate(-float64(i))
+	}
+
+	if acc.Get() != 0 || acc.GetAndReset() != 0 || !math.IsInf(acc.Get(), -1) {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestFloat64AccumulatorRaceMax(t *testing.T) {
+	acc := NewFloat64Accumulator(Float64Max, math.Inf(-1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 1; j <= delta; j++ {
+				acc.Accumulate(-float64(j) / 2)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if acc.GetAndReset() != -0.5 || !math.IsInf(acc.Get(), -1) {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestFloat64AccumulatorRaceMin(t *testing.T) {
+	acc := NewFloat64Accumulator(Float64Min, math.Inf(1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 1; j <= delta; j++ {
+				acc.Accumulate(float64(j) / 2)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if acc.GetAndReset() != 0.5 || !math.IsInf(acc.Get(), 1) {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
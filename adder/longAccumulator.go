@@ -0,0 +1,123 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package adder
+
+import (
+	"sync/atomic"
+)
+
+// LongAccumulator is ported version of OpenJDK9 LongAccumulator.
+//
+// One or more variables, called Cells, together maintain a running int64 value updated using a supplied operator.
+// When updates are contended across routines, the set of variables may grow dynamically to reduce contention.
+// Get (or GetAndReset) returns the current value across the variables maintaining updates.
+//
+// The order of accumulation within or across routines is not guaranteed and cannot be depended upon,
+// so the supplied operator should be associative and commutative (e.g. LongMax, LongMin, LongOr),
+// and side-effect-free.
+//
+// LongAccumulator is high performance, non-blocking and safe for concurrent use.
+type LongAccumulator struct {
+	striped64
+	op       LongBinaryOperator
+	identity int64
+}
+
+// NewLongAccumulator creates new LongAccumulator using the given operator and identity value.
+// For example, NewLongAccumulator(LongMax, math.MinInt64) tracks maximum value.
+func NewLongAccumulator(op LongBinaryOperator, identity int64) *LongAccumulator {
+	return &LongAccumulator{
+		striped64: striped64{base: identity},
+		op:        op,
+		identity:  identity,
+	}
+}
+
+// Accumulate updates with the given value.
+func (u *LongAccumulator) Accumulate(x int64) {
+	_as, uncontended := u.cells.Load(), false
+	if _as != nil {
+		uncontended = true
+	} else if b := atomic.LoadInt64(&u.base); !u.casBaseIfChanged(b, u.op.Apply(b, x)) {
+		uncontended = true
+	}
+
+	if uncontended {
+		if _as == nil {
+			u.accumulate(getRandomInt(), x, u.op, true)
+			return
+		}
+
+		as := _as.(cells)
+		m := len(as) - 1
+		if m < 0 {
+			u.accumulate(getRandomInt(), x, u.op, true)
+			return
+		}
+
+		probe := getRandomInt() & m
+		if _a := as[probe].Load(); _a == nil {
+			u.accumulate(probe, x, u.op, uncontended)
+		} else {
+			a := _a.(*cell)
+
+			v := atomic.LoadInt64(&a.val)
+			if r := u.op.Apply(v, x); r != v && !a.cas(v, r) {
+				u.accumulate(probe, x, u.op, false)
+			}
+		}
+	}
+}
+
+func (u *LongAccumulator) casBaseIfChanged(old, new int64) bool {
+	return old == new || u.casBase(old, new)
+}
+
+// Get returns the current value. The returned value is NOT an
+// atomic snapshot because of concurrent update.
+func (u *LongAccumulator) Get() int64 {
+	result, _as := atomic.LoadInt64(&u.base), u.cells.Load()
+	if _as != nil {
+		as := _as.(cells)
+		var a interface{}
+		for i := range as {
+			if a = as[i].Load(); a != nil {
+				result = u.op.Apply(result, atomic.LoadInt64(&a.(*cell).val))
+			}
+		}
+	}
+	return result
+}
+
+// Reset variables maintaining updates to the identity value. This method may be a useful alternative
+// to creating a new accumulator, but is only effective if there are no concurrent updates.
+func (u *LongAccumulator) Reset() {
+	atomic.StoreInt64(&u.base, u.identity)
+	if _as := u.cells.Load(); _as != nil {
+		cls := make(cells, len(_as.(cells)))
+		for i := range cls {
+			cls[i].Store(&cell{val: u.identity})
+		}
+		u.cells.Store(cls)
+	}
+}
+
+// GetAndReset equivalent in effect to get followed by reset. Like the nature of Get and Reset,
+// this function is only effective if there are no concurrent updates.
+func (u *LongAccumulator) GetAndReset() (v int64) {
+	v = u.Get()
+	u.Reset()
+	return
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package adder
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestLongAccumulatorNotRaceMax(t *testing.T) {
+	acc := NewLongAccumulator(LongMax, math.MinInt64)
+	if acc.Get() != math.MinInt64 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+
+	for i := 0; i < delta; i++ {
+		acc.Accumulate(-int64(i))
+	}
+
+	if acc.Get() != 0 || acc.GetAndReset() != 0 || acc.Get() != math.MinInt64 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestLongAccumulatorRaceMax(t *testing.T) {
+	acc := NewLongAccumulator(LongMax, math.MinInt64)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func(i int) {
+			for j := 0; j < delta; j++ {
+				acc.Accumulate(-int64(j * (i + 1)))
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if acc.Get() != 0 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+
+	// all updates are negative after reset
+	acc.Reset()
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 1; j <= delta; j++ {
+				acc.Accumulate(-int64(j))
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if acc.GetAndReset() != -1 || acc.Get() != math.MinInt64 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestLongAccumulatorRaceMin(t *testing.T) {
+	acc := NewLongAccumulator(LongMin, math.MaxInt64)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 1; j <= delta; j++ {
+				acc.Accumulate(int64(j))
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if acc.GetAndReset() != 1 || acc.Get() != math.MaxInt64 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestLongAccumulatorRaceOr(t *testing.T) {
+	acc := NewLongAccumulator(LongOr, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func(i int) {
+			for j := 0; j < delta; j++ {
+				acc.Accumulate(1 << (j%8*numRoutine + i))
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if acc.GetAndReset() != (1<<(8*numRoutine))-1 || acc.Get() != 0 {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
+
+func TestLongAccumulatorFunc(t *testing.T) {
+	acc := NewLongAccumulator(LongBinaryOperatorFunc(func(left, right int64) int64 {
+		return left + right
+	}), 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numRoutine; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < delta; j++ {
+				acc.Accumulate(1)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if acc.Get() != int64(delta)*int64(numRoutine) {
+		t.Errorf("Accumulator logic is wrong")
+	}
+}
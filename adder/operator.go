@@ -0,0 +1,48 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package adder
+
+import (
+	"math"
+)
+
+var (
+	// LongMax returns the greater of two int64 values. Identity value is math.MinInt64.
+	LongMax LongBinaryOperator = LongBinaryOperatorFunc(func(left, right int64) int64 {
+		if left >= right {
+			return left
+		}
+		return right
+	})
+
+	// LongMin returns the smaller of two int64 values. Identity value is math.MaxInt64.
+	LongMin LongBinaryOperator = LongBinaryOperatorFunc(func(left, right int64) int64 {
+		if left <= right {
+			return left
+		}
+		return right
+	})
+
+	// LongOr returns the bitwise-OR of two int64 values. Identity value is 0.
+	LongOr LongBinaryOperator = LongBinaryOperatorFunc(func(left, right int64) int64 {
+		return left | right
+	})
+
+	// Float64Max returns the greater of two float64 values. Identity value is math.Inf(-1).
+	Float64Max Float64BinaryOperator = Float64BinaryOperatorFunc(math.Max)
+
+	// Float64Min returns the smaller of two float64 values. Identity value is math.Inf(1).
+	Float64Min Float64BinaryOperator = Float64BinaryOperatorFunc(math.Min)
+)
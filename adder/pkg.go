@@ -92,14 +92,30 @@ func NewFloat64Adder(t Type) Float64Adder {
 	}
 }
 
-// longBinaryOperator represents an operation upon two int64-valued operands and producing an
+// LongBinaryOperator represents an operation upon two int64-valued operands and producing an
 // int64-valued result.
-type longBinaryOperator interface {
+type LongBinaryOperator interface {
 	Apply(left, right int64) int64
 }
 
-// floatBinaryOperator represents an operation upon two float64-valued operands and producing an
+// LongBinaryOperatorFunc is an adapter to allow the use of ordinary functions as LongBinaryOperator.
+type LongBinaryOperatorFunc func(left, right int64) int64
+
+// Apply calls f(left, right).
+func (f LongBinaryOperatorFunc) Apply(left, right int64) int64 {
+	return f(left, right)
+}
+
+// Float64BinaryOperator represents an operation upon two float64-valued operands and producing an
 // float64-valued result.
-type floatBinaryOperator interface {
+type Float64BinaryOperator interface {
 	Apply(left, right float64) float64
 }
+
+// Float64BinaryOperatorFunc is an adapter to allow the use of ordinary functions as Float64BinaryOperator.
+type Float64BinaryOperatorFunc func(left, right float64) float64
+
+// Apply calls f(left, right).
+func (f Float64BinaryOperatorFunc) Apply(left, right float64) float64 {
+	return f(left, right)
+}
@@ -70,7 +70,7 @@ func (s *striped64) casCellsBusy() bool {
 	return atomic.CompareAndSwapInt32(&s.cellsBusy, 0, 1)
 }
 
-func (s *striped64) accumulate(index int, x int64, fn longBinaryOperator, wasUncontended bool) {
+func (s *striped64) accumulate(index int, x int64, fn LongBinaryOperator, wasUncontended bool) {
 	if index == 0 {
 		index = getRandomInt()
 		wasUncontended = true
@@ -50,7 +50,7 @@ func (s *stripedF64) casCellsBusy() bool {
 	return atomic.CompareAndSwapInt32(&s.cellsBusy, 0, 1)
 }
 
-func (s *stripedF64) accumulate(probe int, x float64, fn floatBinaryOperator, wasUncontended bool) {
+func (s *stripedF64) accumulate(probe int, x float64, fn Float64BinaryOperator, wasUncontended bool) {
 	if probe == 0 {
 		probe = getRandomInt()
 		wasUncontended = true
@@ -0,0 +1,284 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	ga "go.linecorp.com/garr/adder"
+	"go.linecorp.com/garr/queue"
+)
+
+type listenerEventType byte
+
+const (
+	listenerEventStateChanged listenerEventType = iota
+	listenerEventCountUpdated
+	listenerEventRequestRejected
+	listenerEventRequestCompleted
+)
+
+// listenerEvent is a circuit breaker event buffered by AsyncListener.
+type listenerEvent struct {
+	typ     listenerEventType
+	cb      CircuitBreaker
+	state   CircuitState
+	count   *EventCount
+	outcome Outcome
+	slow    bool
+}
+
+// logger returns the logger of circuit breaker which reported the event.
+func (e *listenerEvent) logger() *slog.Logger {
+	if nb, ok := e.cb.(*NonBlockingCircuitBreaker); ok {
+		return nb.log()
+	}
+	return defaultLogger()
+}
+
+// AsyncListener is a CircuitBreakerListener which buffers events in a bounded queue and delivers them
+// to the underlying listeners on a background goroutine, so that slow listeners do not add latency
+// to the circuit breaker. Events are delivered in the order they are buffered.
+//
+// When the buffer is full, the event is either dropped or the circuit breaker waits for room,
+// depending on OverflowPolicy. With OverflowPolicyBlock, underlying listeners must not report
+// to the circuit breaker themselves, or the delivery may deadlock.
+type AsyncListener struct {
+	listeners          CircuitBreakerListeners
+	hasOutcomeListener bool // buffers RequestCompleted events only if some listener is interested in
+	capacity           int32
+	policy             OverflowPolicy
+
+	events  *queue.JDKLinkedQueueOf[listenerEvent]
+	size    int32 // number of buffered events, including those being offered
+	pending int32 // number of events being buffered
+	dropped ga.JDKAdder
+
+	// blocked producers wait for room, see OverflowPolicyBlock
+	mutex   sync.Mutex
+	room    *sync.Cond
+	waiters int32
+
+	closed    int32
+	notify    chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAsyncListener creates new AsyncListener delivering events to given listeners, buffering at most capacity events.
+func NewAsyncListener(capacity int, policy OverflowPolicy, listeners ...CircuitBreakerListener) (l *AsyncListener, e error) {
+	if capacity <= 0 || capacity > math.MaxInt32 {
+		e = fmt.Errorf("capacity: %d (expected: > 0 and <= %d)", capacity, math.MaxInt32)
+		return
+	}
+	if policy != OverflowPolicyDrop && policy != OverflowPolicyBlock {
+		e = fmt.Errorf("policy: %d (expected: OverflowPolicyDrop or OverflowPolicyBlock)", policy)
+		return
+	}
+
+	l = &AsyncListener{
+		listeners: append(CircuitBreakerListeners(nil), listeners...),
+		capacity:  int32(capacity),
+		policy:    policy,
+		events:    queue.NewJDKLinkedQueueOf[listenerEvent](),
+		notify:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, listener := range l.listeners {
+		if _, ok := listener.(OutcomeListener); ok {
+			l.hasOutcomeListener = true
+		}
+	}
+	l.room = sync.NewCond(&l.mutex)
+	go l.run()
+	return
+}
+
+// OnStateChanged buffers a StateChanged event.
+func (l *AsyncListener) OnStateChanged(cb CircuitBreaker, state CircuitState) (err error) {
+	l.enqueue(listenerEvent{typ: listenerEventStateChanged, cb: cb, state: state})
+	return
+}
+
+// OnEventCountUpdated buffers an EventCountUpdated event.
+func (l *AsyncListener) OnEventCountUpdated(cb CircuitBreaker, eventCount *EventCount) (err error) {
+	l.enqueue(listenerEvent{typ: listenerEventCountUpdated, cb: cb, count: eventCount})
+	return
+}
+
+// OnRequestRejected buffers a RequestRejected event.
+func (l *AsyncListener) OnRequestRejected(cb CircuitBreaker) (err error) {
+	l.enqueue(listenerEvent{typ: listenerEventRequestRejected, cb: cb})
+	return
+}
+
+// OnRequestCompleted buffers a RequestCompleted event, which is delivered to underlying listeners implementing OutcomeListener.
+func (l *AsyncListener) OnRequestCompleted(cb CircuitBreaker, outcome Outcome, slow bool) (err error) {
+	if l.hasOutcomeListener {
+		l.enqueue(listenerEvent{typ: listenerEventRequestCompleted, cb: cb, outcome: outcome, slow: slow})
+	}
+	return
+}
+
+// Stop closes this listener, then stops the underlying listeners.
+func (l *AsyncListener) Stop() {
+	l.Close()
+	for _, listener := range l.listeners {
+		if listener != nil {
+			listener.Stop()
+		}
+	}
+}
+
+// Close stops accepting events and waits until all buffered events are delivered.
+// Events reported after Close are dropped.
+func (l *AsyncListener) Close() {
+	l.closeOnce.Do(func() {
+		atomic.StoreInt32(&l.closed, 1)
+
+		// wakes up blocked producers, which drop their events
+		l.mutex.Lock()
+		l.room.Broadcast()
+		l.mutex.Unlock()
+
+		// waits for events being buffered, so that they are flushed too
+		for atomic.LoadInt32(&l.pending) > 0 {
+			runtime.Gosched()
+		}
+
+		close(l.stop)
+	})
+	<-l.done
+}
+
+// Size returns the number of buffered events.
+func (l *AsyncListener) Size() int32 {
+	return atomic.LoadInt32(&l.size)
+}
+
+// Dropped returns the number of events dropped because the buffer was full or this listener was closed.
+func (l *AsyncListener) Dropped() int64 {
+	return l.dropped.Sum()
+}
+
+func (l *AsyncListener) isClosed() bool {
+	return atomic.LoadInt32(&l.closed) == 1
+}
+
+func (l *AsyncListener) enqueue(event listenerEvent) {
+	atomic.AddInt32(&l.pending, 1)
+	defer atomic.AddInt32(&l.pending, -1)
+
+	if !l.isClosed() && l.reserve() {
+		l.events.Offer(event)
+
+		// wakes up the delivering goroutine, unless it is already notified
+		select {
+		case l.notify <- struct{}{}:
+		default:
+		}
+		return
+	}
+	l.dropped.Inc()
+}
+
+// reserve takes room for an event in the buffer. Returns false if the event should be dropped.
+func (l *AsyncListener) reserve() bool {
+	for {
+		size := atomic.LoadInt32(&l.size)
+		if size < l.capacity {
+			if atomic.CompareAndSwapInt32(&l.size, size, size+1) {
+				return true
+			}
+			continue
+		}
+
+		if l.policy == OverflowPolicyDrop || !l.waitForRoom() {
+			return false
+		}
+	}
+}
+
+// waitForRoom blocks until the buffer is not full. Returns false if this listener is closed meanwhile.
+func (l *AsyncListener) waitForRoom() bool {
+	l.mutex.Lock()
+	atomic.AddInt32(&l.waiters, 1)
+	for atomic.LoadInt32(&l.size) >= l.capacity && !l.isClosed() {
+		l.room.Wait()
+	}
+	atomic.AddInt32(&l.waiters, -1)
+	l.mutex.Unlock()
+	return !l.isClosed()
+}
+
+func (l *AsyncListener) run() {
+	defer close(l.done)
+	for {
+		l.deliverAll()
+		select {
+		case <-l.notify:
+		case <-l.stop:
+			l.deliverAll()
+			return
+		}
+	}
+}
+
+func (l *AsyncListener) deliverAll() {
+	for event, ok := l.events.Poll(); ok; event, ok = l.events.Poll() {
+		atomic.AddInt32(&l.size, -1)
+		if atomic.LoadInt32(&l.waiters) > 0 {
+			l.mutex.Lock()
+			l.room.Broadcast()
+			l.mutex.Unlock()
+		}
+		l.deliver(&event)
+	}
+}
+
+func (l *AsyncListener) deliver(event *listenerEvent) {
+	for _, listener := range l.listeners {
+		if listener == nil {
+			continue
+		}
+
+		var err error
+		var title string
+		switch event.typ {
+		case listenerEventStateChanged:
+			err, title = listener.OnStateChanged(event.cb, event.state), "An error occurred when notifying a StateChanged event"
+		case listenerEventCountUpdated:
+			err, title = listener.OnEventCountUpdated(event.cb, event.count), "An error occurred when notifying an EventCountUpdated event"
+		case listenerEventRequestRejected:
+			err, title = listener.OnRequestRejected(event.cb), "An error occurred when notifying a RequestRejected event"
+		case listenerEventRequestCompleted:
+			if outcomeListener, ok := listener.(OutcomeListener); ok {
+				err, title = outcomeListener.OnRequestCompleted(event.cb, event.outcome, event.slow), "An error occurred when notifying a RequestCompleted event"
+			}
+		}
+		if err != nil {
+			if lg := event.logger(); lg != nil {
+				lg.Warn(title, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingListener records events, optionally waiting for gate before each delivery.
+type recordingListener struct {
+	dummyCircuitBreakerListener
+	mutex    sync.Mutex
+	events   []string
+	gate     chan struct{}
+	entered  chan struct{}
+	stopped  bool
+	failures bool
+}
+
+func (r *recordingListener) record(event string) (err error) {
+	if r.entered != nil {
+		r.entered <- struct{}{}
+	}
+	if r.gate != nil {
+		<-r.gate
+	}
+	r.mutex.Lock()
+	r.events = append(r.events, event)
+	r.mutex.Unlock()
+	if r.failures {
+		err = fmt.Errorf("Fake error")
+	}
+	return
+}
+
+func (r *recordingListener) OnStateChanged(cb CircuitBreaker, state CircuitState) (err error) {
+	return r.record(state.String())
+}
+
+func (r *recordingListener) OnEventCountUpdated(cb CircuitBreaker, eventCount *EventCount) (err error) {
+	return r.record(fmt.Sprintf("count:%d/%d", eventCount.Success(), eventCount.Failure()))
+}
+
+func (r *recordingListener) OnRequestRejected(cb CircuitBreaker) (err error) {
+	return r.record("rejected")
+}
+
+func (r *recordingListener) Stop() {
+	r.stopped = true
+}
+
+func (r *recordingListener) recorded() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestNewAsyncListener(t *testing.T) {
+	if _, err := NewAsyncListener(0, OverflowPolicyDrop); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewAsyncListener(1, OverflowPolicy(2)); err == nil {
+		t.Fatal()
+	}
+
+	l, err := NewAsyncListener(1, OverflowPolicyBlock, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.OnRequestRejected(nil)
+	l.Close()
+	l.Close()
+	if l.Size() != 0 || l.Dropped() != 0 {
+		t.Fatal(l.Size(), l.Dropped())
+	}
+}
+
+func TestAsyncListener_Delivery(t *testing.T) {
+	recorder := &recordingListener{failures: true}
+	l, err := NewAsyncListener(1024, OverflowPolicyBlock, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 2).
+		SetFailureRateThreshold(0.5).
+		SetMinimumRequestThreshold(2).
+		AddListener(l).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb.OnSuccess()
+	cb.OnFailure()
+	cb.OnFailure()
+	cb.CanRequest()
+
+	l.Stop()
+	if !recorder.stopped {
+		t.Fatal()
+	}
+
+	expected := []string{"CLOSED", "count:0/0", "count:1/0", "count:1/1", "OPEN", "count:0/0", "rejected"}
+	if events := recorder.recorded(); fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Fatal(events)
+	}
+
+	// events are dropped after closed
+	cb.CanRequest()
+	if l.Dropped() != 1 || len(recorder.recorded()) != len(expected) {
+		t.Fatal(l.Dropped())
+	}
+}
+
+// outcomeRecordingListener records outcomes too.
+type outcomeRecordingListener struct {
+	recordingListener
+}
+
+func (r *outcomeRecordingListener) OnRequestCompleted(cb CircuitBreaker, outcome Outcome, slow bool) (err error) {
+	return r.record(fmt.Sprintf("completed:%d/%t", outcome, slow))
+}
+
+func TestAsyncListener_RequestCompleted(t *testing.T) {
+	recorder := &outcomeRecordingListener{}
+	l, err := NewAsyncListener(1024, OverflowPolicyBlock, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlowCallDurationThreshold(time.Second).
+		AddListener(l).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb.(ManualCircuitBreaker).Disable()
+	cb.OnFailure()
+	_, _ = cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+		ticker.advance(time.Second)
+		return nil, nil
+	})
+	l.Close()
+
+	// outcomes are notified in any state, e.g. DISABLED, which does not count events
+	expected := []string{"CLOSED", "count:0/0", "DISABLED", "count:0/0", "completed:1/false", "completed:0/true"}
+	if events := recorder.recorded(); fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Fatal(events)
+	}
+
+	// not buffered if no listener is interested in
+	plain, err := NewAsyncListener(1, OverflowPolicyDrop, &recordingListener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	_ = plain.OnRequestCompleted(cb, OutcomeSuccess, false)
+	if plain.Size() != 0 || plain.Dropped() != 0 {
+		t.Fatal(plain.Size(), plain.Dropped())
+	}
+}
+
+func TestAsyncListener_OverflowDrop(t *testing.T) {
+	recorder := &recordingListener{gate: make(chan struct{}), entered: make(chan struct{}, 10)}
+	l, _ := NewAsyncListener(1, OverflowPolicyDrop, recorder)
+
+	_ = l.OnRequestRejected(nil)
+	<-recorder.entered // the first event is being delivered
+
+	_ = l.OnStateChanged(nil, CircuitStateOpen)
+	_ = l.OnStateChanged(nil, CircuitStateHalfOpen)
+	if l.Size() != 1 || l.Dropped() != 1 {
+		t.Fatal(l.Size(), l.Dropped())
+	}
+
+	close(recorder.gate)
+	l.Close()
+	if events := recorder.recorded(); fmt.Sprint(events) != "[rejected OPEN]" {
+		t.Fatal(events)
+	}
+}
+
+func TestAsyncListener_OverflowBlock(t *testing.T) {
+	recorder := &recordingListener{gate: make(chan struct{}), entered: make(chan struct{}, 10)}
+	l, _ := NewAsyncListener(1, OverflowPolicyBlock, recorder)
+
+	_ = l.OnRequestRejected(nil)
+	<-recorder.entered // the first event is being delivered
+	_ = l.OnStateChanged(nil, CircuitStateOpen)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	blocked := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		close(blocked)
+		_ = l.OnStateChanged(nil, CircuitStateHalfOpen)
+	}()
+	<-blocked
+
+	time.Sleep(10 * time.Millisecond)
+	if l.Size() != 1 || len(recorder.recorded()) != 0 {
+		t.Fatal(l.Size())
+	}
+
+	close(recorder.gate)
+	wg.Wait()
+	l.Close()
+	if events := recorder.recorded(); fmt.Sprint(events) != "[rejected OPEN HALF_OPEN]" || l.Dropped() != 0 {
+		t.Fatal(events, l.Dropped())
+	}
+}
+
+func TestAsyncListener_CloseUnblocks(t *testing.T) {
+	recorder := &recordingListener{gate: make(chan struct{}), entered: make(chan struct{}, 10)}
+	l, _ := NewAsyncListener(1, OverflowPolicyBlock, recorder)
+
+	_ = l.OnRequestRejected(nil)
+	<-recorder.entered
+	_ = l.OnRequestRejected(nil)
+
+	done := make(chan struct{})
+	go func() {
+		_ = l.OnRequestRejected(nil)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	go l.Close()
+	<-done // the blocked producer drops its event
+
+	close(recorder.gate)
+	l.Close()
+	if events := recorder.recorded(); len(events) != 2 || l.Dropped() != 1 {
+		t.Fatal(events, l.Dropped())
+	}
+}
+
+func TestAsyncListener_Concurrent(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowPolicyDrop, OverflowPolicyBlock} {
+		recorder := &recordingListener{}
+		l, _ := NewAsyncListener(8, policy, recorder)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					_ = l.OnRequestRejected(nil)
+				}
+			}()
+		}
+		wg.Wait()
+		l.Close()
+
+		if delivered := int64(len(recorder.recorded())); delivered+l.Dropped() != 8000 ||
+			(policy == OverflowPolicyBlock && l.Dropped() != 0) || l.Size() != 0 {
+			t.Fatal(policy, delivered, l.Dropped())
+		}
+	}
+}
@@ -35,6 +35,17 @@ type Name struct {
 // Execute function.
 type Execute func(ctx context.Context) (result interface{}, err error)
 
+// Classifier decides the Outcome of a call made through Execute from its result.
+type Classifier func(result interface{}, err error) Outcome
+
+// DefaultClassifier records a call as a failure if err is not nil, otherwise as a success.
+func DefaultClassifier(result interface{}, err error) Outcome {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
 // CircuitBreaker tracks the number of success/failure requests and detects a remote service failure.
 type CircuitBreaker interface {
 	// Name return the name of the circuit breaker.
@@ -45,10 +56,27 @@ type CircuitBreaker interface {
 	OnFailure()
 	// CanRequest decide whether a request should be sent or failed depending on the current circuit state.
 	CanRequest() bool
-	// Execute delegated function.
+	// Execute delegated function and records its outcome, if a request can be sent.
+	// Otherwise returns ErrFailFast without calling the function.
 	Execute(ctx context.Context, delegatedFn Execute) (r interface{}, err error)
 }
 
+// ManualCircuitBreaker is a CircuitBreaker whose state could be inspected and changed manually by operators.
+// NonBlockingCircuitBreaker implements it.
+type ManualCircuitBreaker interface {
+	CircuitBreaker
+	// State returns the current circuit state.
+	State() CircuitState
+	// ForceOpen opens the circuit until the state is changed manually.
+	ForceOpen()
+	// ForceClosed closes the circuit, keeping the number of consecutive OPEN states used by open window backoff.
+	ForceClosed()
+	// Disable allows all requests without counting events until the state is changed manually.
+	Disable()
+	// Reset returns the circuit breaker to its initial state, losing all counted events and consecutive OPEN states.
+	Reset()
+}
+
 // CircuitBreakerListener is listener interface for receiving events.
 type CircuitBreakerListener interface {
 	// OnStateChanged invoked when the circuit state is changed.
@@ -61,5 +89,15 @@ type CircuitBreakerListener interface {
 	Stop()
 }
 
+// OutcomeListener is a CircuitBreakerListener which is also notified of every outcome reported to the circuit breaker,
+// whatever the circuit state is, e.g. to keep monotonic counters of requests. Listeners which do not implement it
+// only see outcomes through the EventCount of the current sliding window.
+type OutcomeListener interface {
+	CircuitBreakerListener
+	// OnRequestCompleted invoked when a success or a failure is reported, slow if the call made through Execute
+	// took longer than slow call duration threshold.
+	OnRequestCompleted(cb CircuitBreaker, outcome Outcome, slow bool) (err error)
+}
+
 // CircuitBreakerListeners is collection of CircuitBreakerListener.
 type CircuitBreakerListeners []CircuitBreakerListener
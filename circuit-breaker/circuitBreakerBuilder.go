@@ -15,32 +15,48 @@
 package cbreaker
 
 import (
+	"log/slog"
 	"time"
+
+	"go.linecorp.com/garr/retry"
 )
 
 // CircuitBreakerBuilder is the builder for CircuitBreaker, using builder-pattern.
 type CircuitBreakerBuilder struct {
-	name                    *Name
-	ticker                  Ticker
-	failureRateThreshold    float64
-	minimumRequestThreshold int64
-	trialRequestInterval    time.Duration
-	circuitOpenWindow       time.Duration
-	counterSlidingWindow    time.Duration
-	counterUpdateInterval   time.Duration
-	listeners               CircuitBreakerListeners
+	name                      *Name
+	ticker                    Ticker
+	failureRateThreshold      float64
+	minimumRequestThreshold   int64
+	trialRequestInterval      time.Duration
+	circuitOpenWindow         time.Duration
+	circuitOpenWindowBackoff  retry.Backoff
+	counterSlidingWindow      time.Duration
+	counterUpdateInterval     time.Duration
+	slidingWindowType         SlidingWindowType
+	slidingWindowSize         int
+	slowCallDurationThreshold time.Duration
+	slowCallRateThreshold     float64
+	classifier                Classifier
+	permittedTrialRequests    int
+	trialSuccessRateThreshold float64
+	listeners                 CircuitBreakerListeners
+	logger                    *slog.Logger
 }
 
 // NewCircuitBreakerBuilder creates new circuit breaker builder.
 func NewCircuitBreakerBuilder() (c *CircuitBreakerBuilder) {
 	c = &CircuitBreakerBuilder{
-		ticker:                  SystemTicker,
-		failureRateThreshold:    defaultFailureRateThreshold,
-		minimumRequestThreshold: defaultMinimumRequestThreshold,
-		trialRequestInterval:    defaultTrialRequestInterval,
-		circuitOpenWindow:       defaultCircuitOpenWindow,
-		counterSlidingWindow:    defaultCounterSlidingWindow,
-		counterUpdateInterval:   defaultCounterUpdateInterval,
+		ticker:                    SystemTicker,
+		failureRateThreshold:      defaultFailureRateThreshold,
+		minimumRequestThreshold:   defaultMinimumRequestThreshold,
+		trialRequestInterval:      defaultTrialRequestInterval,
+		circuitOpenWindow:         defaultCircuitOpenWindow,
+		counterSlidingWindow:      defaultCounterSlidingWindow,
+		counterUpdateInterval:     defaultCounterUpdateInterval,
+		slidingWindowSize:         defaultSlidingWindowSize,
+		slowCallRateThreshold:     defaultSlowCallRateThreshold,
+		permittedTrialRequests:    defaultPermittedTrialRequests,
+		trialSuccessRateThreshold: defaultTrialSuccessRate,
 	}
 	return
 }
@@ -81,6 +97,16 @@ func (c *CircuitBreakerBuilder) SetCircuitOpenWindow(circuitOpenWindow time.Dura
 	return c
 }
 
+// SetCircuitOpenWindowBackoff sets the backoff of Open state duration. The duration grows with the number of
+// consecutive Open states, i.e. after each failed trial in HalfOpen state, and resets when the circuit is closed.
+// If the backoff returns a non-positive delay, e.g. it is limited, the last open window is kept, or the fixed
+// circuit open window is used if there is none yet.
+// A retry.StatefulBackoff is made fresh for every circuit breaker, whenever it is closed.
+func (c *CircuitBreakerBuilder) SetCircuitOpenWindowBackoff(backoff retry.Backoff) *CircuitBreakerBuilder {
+	c.circuitOpenWindowBackoff = backoff
+	return c
+}
+
 // SetCounterSlidingWindow sets the time length of sliding window to accumulate the count of events.
 func (c *CircuitBreakerBuilder) SetCounterSlidingWindow(counterSlidingWindow time.Duration) *CircuitBreakerBuilder {
 	c.counterSlidingWindow = counterSlidingWindow
@@ -93,6 +119,68 @@ func (c *CircuitBreakerBuilder) SetCounterUpdateInterval(counterUpdateInterval t
 	return c
 }
 
+// SetSlidingWindowType sets how the count of events is accumulated. For SlidingWindowCountBased,
+// size is the number of last calls to accumulate. For SlidingWindowTimeBased, size is ignored and
+// the window is configured by SetCounterSlidingWindow and SetCounterUpdateInterval.
+func (c *CircuitBreakerBuilder) SetSlidingWindowType(slidingWindowType SlidingWindowType, size int) *CircuitBreakerBuilder {
+	c.slidingWindowType = slidingWindowType
+	if slidingWindowType == SlidingWindowCountBased {
+		c.slidingWindowSize = size
+	}
+	return c
+}
+
+// SetSlowCallDurationThreshold sets the duration above which calls made through Execute are considered as slow.
+// Zero disables slow call detection, which is the default.
+func (c *CircuitBreakerBuilder) SetSlowCallDurationThreshold(slowCallDurationThreshold time.Duration) *CircuitBreakerBuilder {
+	c.slowCallDurationThreshold = slowCallDurationThreshold
+	return c
+}
+
+// SetSlowCallRateThreshold sets the threshold of slow call rate to detect a remote service fault.
+func (c *CircuitBreakerBuilder) SetSlowCallRateThreshold(slowCallRateThreshold float64) *CircuitBreakerBuilder {
+	c.slowCallRateThreshold = slowCallRateThreshold
+	return c
+}
+
+// SetClassifier sets the Classifier deciding whether a call made through Execute is recorded
+// as a success, a failure or ignored. Default to DefaultClassifier.
+func (c *CircuitBreakerBuilder) SetClassifier(classifier Classifier) *CircuitBreakerBuilder {
+	c.classifier = classifier
+	return c
+}
+
+// SetPermittedTrialRequests sets the number of trial requests permitted in HalfOpen state,
+// similar to resilience4j's permittedNumberOfCallsInHalfOpenState. Default to 1.
+func (c *CircuitBreakerBuilder) SetPermittedTrialRequests(permittedTrialRequests int) *CircuitBreakerBuilder {
+	c.permittedTrialRequests = permittedTrialRequests
+	return c
+}
+
+// SetTrialSuccessRateThreshold sets the ratio of permitted trial requests which must succeed to close the circuit.
+// The circuit is opened again as soon as the ratio can no longer be reached. Default to 1, which requires all of them to succeed.
+func (c *CircuitBreakerBuilder) SetTrialSuccessRateThreshold(trialSuccessRateThreshold float64) *CircuitBreakerBuilder {
+	c.trialSuccessRateThreshold = trialSuccessRateThreshold
+	return c
+}
+
+// SetLogger sets the logger of circuit breaker, which logs state transitions with structured attributes
+// and errors returned by listeners. If it is not set, the default logger set by SetDefaultLogger is used.
+func (c *CircuitBreakerBuilder) SetLogger(logger *slog.Logger) *CircuitBreakerBuilder {
+	c.logger = logger
+	return c
+}
+
+// SetLogHandler sets the logger of circuit breaker to a *slog.Logger writing to given handler. See SetLogger.
+func (c *CircuitBreakerBuilder) SetLogHandler(handler slog.Handler) *CircuitBreakerBuilder {
+	if handler == nil {
+		c.logger = nil
+	} else {
+		c.logger = slog.New(handler)
+	}
+	return c
+}
+
 // AddListener adds a CircuitBreakerListener.
 func (c *CircuitBreakerBuilder) AddListener(listener CircuitBreakerListener) *CircuitBreakerBuilder {
 	if listener != nil {
@@ -103,15 +191,38 @@ func (c *CircuitBreakerBuilder) AddListener(listener CircuitBreakerListener) *Ci
 
 // Build returns a newly-created CircuitBreaker based on the properties of this builder.
 func (c *CircuitBreakerBuilder) Build() (cb CircuitBreaker, err error) {
-	cb, err = NewNonBlockingCircuitBreaker(c.ticker, &CircuitBreakerConfig{
-		name:                    c.name,
-		failureRateThreshold:    c.failureRateThreshold,
-		minimumRequestThreshold: c.minimumRequestThreshold,
-		trialRequestInterval:    c.trialRequestInterval,
-		circuitOpenWindow:       c.circuitOpenWindow,
-		counterSlidingWindow:    c.counterSlidingWindow,
-		counterUpdateInterval:   c.counterUpdateInterval,
-		listeners:               c.listeners,
-	})
+	cb, err = NewNonBlockingCircuitBreaker(c.ticker, c.config())
 	return
 }
+
+// BuildConfig returns a newly-created and validated CircuitBreakerConfig based on the properties of this builder,
+// e.g. to be used as a template of CircuitBreakerRegistry. Ticker is not part of the config.
+func (c *CircuitBreakerBuilder) BuildConfig() (config *CircuitBreakerConfig, err error) {
+	config = c.config()
+	if err = config.Validate(); err != nil {
+		config = nil
+	}
+	return
+}
+
+func (c *CircuitBreakerBuilder) config() *CircuitBreakerConfig {
+	return &CircuitBreakerConfig{
+		name:                      c.name,
+		failureRateThreshold:      c.failureRateThreshold,
+		minimumRequestThreshold:   c.minimumRequestThreshold,
+		trialRequestInterval:      c.trialRequestInterval,
+		circuitOpenWindow:         c.circuitOpenWindow,
+		circuitOpenWindowBackoff:  c.circuitOpenWindowBackoff,
+		counterSlidingWindow:      c.counterSlidingWindow,
+		counterUpdateInterval:     c.counterUpdateInterval,
+		slidingWindowType:         c.slidingWindowType,
+		slidingWindowSize:         c.slidingWindowSize,
+		slowCallDurationThreshold: c.slowCallDurationThreshold,
+		slowCallRateThreshold:     c.slowCallRateThreshold,
+		classifier:                c.classifier,
+		permittedTrialRequests:    c.permittedTrialRequests,
+		trialSuccessRateThreshold: c.trialSuccessRateThreshold,
+		logger:                    c.logger,
+		listeners:                 c.listeners,
+	}
+}
@@ -147,3 +147,55 @@ func (d *dummyCircuitBreakerListener) OnRequestRejected(cb CircuitBreaker) (err
 // Stop listening
 func (d *dummyCircuitBreakerListener) Stop() {
 }
+
+func TestBuilderSetSlidingWindowType(t *testing.T) {
+	builder := NewCircuitBreakerBuilder()
+
+	if _, err := builder.SetSlidingWindowType(SlidingWindowCountBased, 0).Build(); err == nil {
+		t.Errorf("Fail to set SlidingWindowType")
+	} else if _, err = builder.SetSlidingWindowType(SlidingWindowCountBased, 50).Build(); err != nil || builder.slidingWindowSize != 50 {
+		t.Errorf("Fail to set SlidingWindowType")
+	}
+
+	// size is ignored for time based window
+	if _, err := builder.SetSlidingWindowType(SlidingWindowTimeBased, 0).Build(); err != nil || builder.slidingWindowSize != 50 {
+		t.Errorf("Fail to set SlidingWindowType")
+	}
+}
+
+func TestBuilderSetSlowCall(t *testing.T) {
+	builder := NewCircuitBreakerBuilder()
+
+	if _, err := builder.SetSlowCallDurationThreshold(-1).Build(); err == nil {
+		t.Errorf("Fail to set SlowCallDurationThreshold")
+	} else if _, err = builder.SetSlowCallDurationThreshold(time.Second).SetSlowCallRateThreshold(0).Build(); err == nil {
+		t.Errorf("Fail to set SlowCallRateThreshold")
+	} else if _, err = builder.SetSlowCallRateThreshold(1.1).Build(); err == nil {
+		t.Errorf("Fail to set SlowCallRateThreshold")
+	} else if _, err = builder.SetSlowCallRateThreshold(0.5).Build(); err != nil ||
+		builder.slowCallDurationThreshold != time.Second || builder.slowCallRateThreshold != 0.5 {
+		t.Errorf("Fail to set SlowCall")
+	}
+}
+
+func TestBuilderSetTrialRequests(t *testing.T) {
+	builder := NewCircuitBreakerBuilder()
+
+	if _, err := builder.SetPermittedTrialRequests(-1).Build(); err == nil {
+		t.Errorf("Fail to set PermittedTrialRequests")
+	} else if _, err = builder.SetPermittedTrialRequests(10).SetTrialSuccessRateThreshold(1.1).Build(); err == nil {
+		t.Errorf("Fail to set TrialSuccessRateThreshold")
+	} else if _, err = builder.SetTrialSuccessRateThreshold(0.7).Build(); err != nil {
+		t.Errorf("Fail to set TrialSuccessRateThreshold")
+	}
+
+	config := &CircuitBreakerConfig{permittedTrialRequests: 10, trialSuccessRateThreshold: 0.7}
+	if config.requiredTrialSuccesses() != 7 {
+		t.Error(config.requiredTrialSuccesses())
+	}
+
+	// zero values fall back to defaults, a single success closes the circuit
+	if config = (&CircuitBreakerConfig{}); config.GetPermittedTrialRequests() != 1 || config.requiredTrialSuccesses() != 1 {
+		t.Error(config.requiredTrialSuccesses())
+	}
+}
@@ -16,19 +16,32 @@ package cbreaker
 
 import (
 	"fmt"
+	"log/slog"
+	"math"
 	"time"
+
+	"go.linecorp.com/garr/retry"
 )
 
 // CircuitBreakerConfig stores configurations of circuit breaker.
 type CircuitBreakerConfig struct {
-	name                    *Name
-	failureRateThreshold    float64
-	minimumRequestThreshold int64
-	trialRequestInterval    time.Duration
-	circuitOpenWindow       time.Duration
-	counterSlidingWindow    time.Duration
-	counterUpdateInterval   time.Duration
-	listeners               CircuitBreakerListeners
+	name                      *Name
+	failureRateThreshold      float64
+	minimumRequestThreshold   int64
+	trialRequestInterval      time.Duration
+	circuitOpenWindow         time.Duration
+	circuitOpenWindowBackoff  retry.Backoff
+	counterSlidingWindow      time.Duration
+	counterUpdateInterval     time.Duration
+	slidingWindowType         SlidingWindowType
+	slidingWindowSize         int
+	slowCallDurationThreshold time.Duration
+	slowCallRateThreshold     float64
+	classifier                Classifier
+	permittedTrialRequests    int
+	trialSuccessRateThreshold float64
+	listeners                 CircuitBreakerListeners
+	logger                    *slog.Logger
 }
 
 // GetName returns name of CircuitBreaker.
@@ -57,6 +70,11 @@ func (c *CircuitBreakerConfig) GetCircuitOpenWindow() time.Duration {
 	return c.circuitOpenWindow
 }
 
+// GetCircuitOpenWindowBackoff returns the backoff of Open state duration, or nil if the duration is fixed.
+func (c *CircuitBreakerConfig) GetCircuitOpenWindowBackoff() retry.Backoff {
+	return c.circuitOpenWindowBackoff
+}
+
 // GetCounterSlidingWindow returns the time length of sliding window to accumulate the count of events.
 func (c *CircuitBreakerConfig) GetCounterSlidingWindow() time.Duration {
 	return c.counterSlidingWindow
@@ -67,11 +85,61 @@ func (c *CircuitBreakerConfig) GetCounterUpdateInterval() time.Duration {
 	return c.counterUpdateInterval
 }
 
+// GetSlidingWindowType returns how the count of events is accumulated.
+func (c *CircuitBreakerConfig) GetSlidingWindowType() SlidingWindowType {
+	return c.slidingWindowType
+}
+
+// GetSlidingWindowSize returns the number of calls of count-based sliding window.
+func (c *CircuitBreakerConfig) GetSlidingWindowSize() int {
+	return c.slidingWindowSize
+}
+
+// GetSlowCallDurationThreshold returns the duration above which calls are considered as slow.
+// Zero indicates slow call detection is disabled.
+func (c *CircuitBreakerConfig) GetSlowCallDurationThreshold() time.Duration {
+	return c.slowCallDurationThreshold
+}
+
+// GetSlowCallRateThreshold returns the threshold of slow call rate to detect a remote service fault.
+func (c *CircuitBreakerConfig) GetSlowCallRateThreshold() float64 {
+	return c.slowCallRateThreshold
+}
+
+// GetClassifier returns the Classifier deciding the outcome of calls made through Execute.
+func (c *CircuitBreakerConfig) GetClassifier() Classifier {
+	if c.classifier == nil {
+		return DefaultClassifier
+	}
+	return c.classifier
+}
+
+// GetPermittedTrialRequests returns the number of trial requests permitted in HalfOpen state.
+func (c *CircuitBreakerConfig) GetPermittedTrialRequests() int {
+	if c.permittedTrialRequests <= 0 {
+		return defaultPermittedTrialRequests
+	}
+	return c.permittedTrialRequests
+}
+
+// GetTrialSuccessRateThreshold returns the ratio of permitted trial requests which must succeed to close the circuit.
+func (c *CircuitBreakerConfig) GetTrialSuccessRateThreshold() float64 {
+	if c.trialSuccessRateThreshold <= 0 {
+		return defaultTrialSuccessRate
+	}
+	return c.trialSuccessRateThreshold
+}
+
 // Getlisteners returns CircuitBreakerListener(s)
 func (c *CircuitBreakerConfig) Getlisteners() CircuitBreakerListeners {
 	return c.listeners
 }
 
+// GetLogger returns the *slog.Logger of circuit breaker, nil if it uses the default logger.
+func (c *CircuitBreakerConfig) GetLogger() *slog.Logger {
+	return c.logger
+}
+
 // Validate current configuration.
 func (c *CircuitBreakerConfig) Validate() (err error) {
 	if c.failureRateThreshold <= 0 || 1 < c.failureRateThreshold {
@@ -89,6 +157,33 @@ func (c *CircuitBreakerConfig) Validate() (err error) {
 		return
 	}
 
+	if c.slowCallDurationThreshold < 0 {
+		err = fmt.Errorf("slowCallDurationThreshold: %d (expected: >= 0)", c.slowCallDurationThreshold)
+		return
+	}
+
+	if c.slowCallDurationThreshold > 0 && (c.slowCallRateThreshold <= 0 || 1 < c.slowCallRateThreshold) {
+		err = fmt.Errorf("slowCallRateThreshold: %.3f (expected: > 0 and <= 1)", c.slowCallRateThreshold)
+		return
+	}
+
+	if c.permittedTrialRequests < 0 {
+		err = fmt.Errorf("permittedTrialRequests: %d (expected: >= 0, 0 for default)", c.permittedTrialRequests)
+		return
+	}
+
+	if c.trialSuccessRateThreshold < 0 || 1 < c.trialSuccessRateThreshold {
+		err = fmt.Errorf("trialSuccessRateThreshold: %.3f (expected: >= 0 and <= 1, 0 for default)", c.trialSuccessRateThreshold)
+		return
+	}
+
+	if c.slidingWindowType == SlidingWindowCountBased {
+		if c.slidingWindowSize <= 0 {
+			err = fmt.Errorf("slidingWindowSize: %d (expected: > 0)", c.slidingWindowSize)
+		}
+		return
+	}
+
 	if c.counterSlidingWindow <= 0 {
 		err = fmt.Errorf("counterSlidingWindow: %d (expected: > 0)", c.counterSlidingWindow)
 		return
@@ -109,9 +204,59 @@ func (c *CircuitBreakerConfig) Validate() (err error) {
 
 // String is stringer interface of CircuitBreakerConfig.
 func (c *CircuitBreakerConfig) String() string {
-	return fmt.Sprintf("name: %s, failureRateThreshold: %.3f, minimumRequestThreshold: %d, trialRequestInterval: %d, circuitOpenWindow: %d, counterSlidingWindow: %d, counterUpdateInterval: %d",
+	return fmt.Sprintf("name: %s, failureRateThreshold: %.3f, minimumRequestThreshold: %d, trialRequestInterval: %d, circuitOpenWindow: %d, counterSlidingWindow: %d, counterUpdateInterval: %d, slidingWindowType: %d, slidingWindowSize: %d, slowCallDurationThreshold: %d, slowCallRateThreshold: %.3f, permittedTrialRequests: %d, trialSuccessRateThreshold: %.3f",
 		c.name, c.failureRateThreshold, c.minimumRequestThreshold,
 		c.trialRequestInterval, c.circuitOpenWindow,
 		c.counterSlidingWindow, c.counterUpdateInterval,
+		c.slidingWindowType, c.slidingWindowSize,
+		c.slowCallDurationThreshold, c.slowCallRateThreshold,
+		c.GetPermittedTrialRequests(), c.GetTrialSuccessRateThreshold(),
 	)
 }
+
+// minimumRequests returns the minimum number of requests necessary to detect a remote service fault.
+// For count-based sliding window, it is capped by the window size, otherwise the window could never trip.
+func (c *CircuitBreakerConfig) minimumRequests() int64 {
+	if c.slidingWindowType == SlidingWindowCountBased && int64(c.slidingWindowSize) < c.minimumRequestThreshold {
+		return int64(c.slidingWindowSize)
+	}
+	return c.minimumRequestThreshold
+}
+
+// requiredTrialSuccesses returns the number of trial requests which must succeed to close the circuit.
+func (c *CircuitBreakerConfig) requiredTrialSuccesses() int64 {
+	permitted := c.GetPermittedTrialRequests()
+
+	// tolerates floating point error, e.g. 0.7 * 10
+	required := int(math.Ceil(c.GetTrialSuccessRateThreshold()*float64(permitted) - 1e-9))
+	if required < 1 {
+		required = 1
+	} else if required > permitted {
+		required = permitted
+	}
+	return int64(required)
+}
+
+// freshCircuitOpenWindowBackoff returns a fresh instance of circuitOpenWindowBackoff, so that a stateful backoff
+// is not shared by circuit breakers built from this config. Returns nil if there is no backoff.
+func (c *CircuitBreakerConfig) freshCircuitOpenWindowBackoff() retry.DurationBackoff {
+	if c.circuitOpenWindowBackoff == nil {
+		return nil
+	}
+	return retry.AsDurationBackoff(retry.Fresh(c.circuitOpenWindowBackoff))
+}
+
+// circuitOpenWindowFor returns the duration of the given consecutive Open state, starting from 1, by the given
+// fresh instance of circuitOpenWindowBackoff. Once the backoff gives up, the last open window, if any, is kept so that
+// the window does not shrink. Falls back to circuitOpenWindow if there is no backoff or no last open window.
+func (c *CircuitBreakerConfig) circuitOpenWindowFor(backoff retry.DurationBackoff, attempts int, last time.Duration) time.Duration {
+	if backoff != nil {
+		if delay := backoff.NextDelay(attempts); delay > 0 {
+			return delay
+		}
+		if last > 0 {
+			return last
+		}
+	}
+	return c.circuitOpenWindow
+}
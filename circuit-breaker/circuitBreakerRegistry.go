@@ -0,0 +1,254 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// CircuitBreakerRegistry creates and indexes circuit breakers by Name, e.g. one per upstream host.
+//
+// Circuit breakers are created lazily from the default config, a named config template or
+// a per-name override. Registry-wide listeners are notified of events of all circuit breakers,
+// including ones created before the listener is added.
+type CircuitBreakerRegistry struct {
+	ticker        Ticker
+	defaultConfig *CircuitBreakerConfig
+
+	mutex     sync.RWMutex
+	templates map[string]*CircuitBreakerConfig
+	overrides map[Name]*CircuitBreakerConfig
+	breakers  map[Name]CircuitBreaker
+
+	listeners atomic.Value // CircuitBreakerListeners, copy-on-write
+}
+
+// NewCircuitBreakerRegistry creates new CircuitBreakerRegistry. If ticker is nil, SystemTicker is used.
+// If defaultConfig is nil, the default config of CircuitBreakerBuilder is used.
+func NewCircuitBreakerRegistry(ticker Ticker, defaultConfig *CircuitBreakerConfig) (r *CircuitBreakerRegistry, err error) {
+	if ticker == nil {
+		ticker = SystemTicker
+	}
+
+	if defaultConfig == nil {
+		defaultConfig = NewCircuitBreakerBuilder().config()
+	} else if err = defaultConfig.Validate(); err != nil {
+		return
+	}
+
+	r = &CircuitBreakerRegistry{
+		ticker:        ticker,
+		defaultConfig: defaultConfig,
+		templates:     make(map[string]*CircuitBreakerConfig),
+		overrides:     make(map[Name]*CircuitBreakerConfig),
+		breakers:      make(map[Name]CircuitBreaker),
+	}
+	r.listeners.Store(CircuitBreakerListeners(nil))
+	return
+}
+
+// AddTemplate adds a named config template, which could be used by GetOrCreateWithTemplate.
+func (r *CircuitBreakerRegistry) AddTemplate(template string, config *CircuitBreakerConfig) error {
+	if err := validateRegistryConfig(config); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.templates[template] = config
+	r.mutex.Unlock()
+	return nil
+}
+
+// Override sets the config of circuit breaker with the given name, which takes precedence over
+// the default config and templates. It takes effect on circuit breakers created afterwards.
+func (r *CircuitBreakerRegistry) Override(name *Name, config *CircuitBreakerConfig) error {
+	if name == nil {
+		return fmt.Errorf("Name is required")
+	}
+	if err := validateRegistryConfig(config); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.overrides[*name] = config
+	r.mutex.Unlock()
+	return nil
+}
+
+// AddListener adds a registry-wide CircuitBreakerListener, which is notified of events of all circuit breakers.
+func (r *CircuitBreakerRegistry) AddListener(listener CircuitBreakerListener) {
+	if listener == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	old := r.listeners.Load().(CircuitBreakerListeners)
+	listeners := make(CircuitBreakerListeners, len(old), len(old)+1)
+	copy(listeners, old)
+	r.listeners.Store(append(listeners, listener))
+	r.mutex.Unlock()
+}
+
+// Get returns circuit breaker with the given name, if exists.
+func (r *CircuitBreakerRegistry) Get(name *Name) (cb CircuitBreaker, ok bool) {
+	if name != nil {
+		r.mutex.RLock()
+		cb, ok = r.breakers[*name]
+		r.mutex.RUnlock()
+	}
+	return
+}
+
+// GetOrCreate returns circuit breaker with the given name, creating it from the per-name override
+// or the default config if not exists. If concurrent calls create the same circuit breaker, only one of them
+// is kept, though listeners may be notified of the initial state of the others too.
+func (r *CircuitBreakerRegistry) GetOrCreate(name *Name) (CircuitBreaker, error) {
+	return r.getOrCreate(name, "")
+}
+
+// GetOrCreateWithTemplate returns circuit breaker with the given name, creating it from the per-name override
+// or the given config template if not exists. Returns error if the template does not exist.
+func (r *CircuitBreakerRegistry) GetOrCreateWithTemplate(name *Name, template string) (CircuitBreaker, error) {
+	return r.getOrCreate(name, template)
+}
+
+func (r *CircuitBreakerRegistry) getOrCreate(name *Name, template string) (cb CircuitBreaker, err error) {
+	if name == nil {
+		err = fmt.Errorf("Name is required")
+		return
+	}
+
+	var ok bool
+	if cb, ok = r.Get(name); ok {
+		return
+	}
+
+	r.mutex.RLock()
+	config := r.defaultConfig
+	if override, ok := r.overrides[*name]; ok {
+		config = override
+	} else if template != "" {
+		if config, ok = r.templates[template]; !ok {
+			err = fmt.Errorf("Template %q does not exist", template)
+		}
+	}
+	r.mutex.RUnlock()
+	if err != nil {
+		return
+	}
+
+	// each circuit breaker owns its name and notifies registry-wide listeners
+	n := *name
+	c := *config
+	c.name = &n
+	c.listeners = append(append(make(CircuitBreakerListeners, 0, len(config.listeners)+1), config.listeners...), registryListener{r})
+
+	// built outside the lock, since listeners notified of the initial state could use this registry
+	nbc, err := NewNonBlockingCircuitBreaker(r.ticker, &c)
+	if err != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// another goroutine may have created one meanwhile, which wins
+	if cb, ok = r.breakers[n]; !ok {
+		r.breakers[n] = nbc
+		cb = nbc
+	}
+	return
+}
+
+// Remove removes circuit breaker with the given name from this registry. Returns false if not exists.
+// The removed circuit breaker keeps working, but the next GetOrCreate creates a new one.
+func (r *CircuitBreakerRegistry) Remove(name *Name) (ok bool) {
+	if name != nil {
+		r.mutex.Lock()
+		if _, ok = r.breakers[*name]; ok {
+			delete(r.breakers, *name)
+		}
+		r.mutex.Unlock()
+	}
+	return
+}
+
+// All returns an iterator over a snapshot of circuit breakers in this registry, in no particular order.
+func (r *CircuitBreakerRegistry) All() iter.Seq[CircuitBreaker] {
+	r.mutex.RLock()
+	breakers := make([]CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mutex.RUnlock()
+
+	return func(yield func(CircuitBreaker) bool) {
+		for _, cb := range breakers {
+			if !yield(cb) {
+				return
+			}
+		}
+	}
+}
+
+func validateRegistryConfig(config *CircuitBreakerConfig) error {
+	if config == nil {
+		return fmt.Errorf("Config is required")
+	}
+	return config.Validate()
+}
+
+// registryListener forwards events to registry-wide listeners, joining their errors.
+type registryListener struct {
+	r *CircuitBreakerRegistry
+}
+
+func (l registryListener) OnStateChanged(cb CircuitBreaker, state CircuitState) (err error) {
+	for _, listener := range l.r.listeners.Load().(CircuitBreakerListeners) {
+		err = errors.Join(err, listener.OnStateChanged(cb, state))
+	}
+	return
+}
+
+func (l registryListener) OnEventCountUpdated(cb CircuitBreaker, eventCount *EventCount) (err error) {
+	for _, listener := range l.r.listeners.Load().(CircuitBreakerListeners) {
+		err = errors.Join(err, listener.OnEventCountUpdated(cb, eventCount))
+	}
+	return
+}
+
+func (l registryListener) OnRequestRejected(cb CircuitBreaker) (err error) {
+	for _, listener := range l.r.listeners.Load().(CircuitBreakerListeners) {
+		err = errors.Join(err, listener.OnRequestRejected(cb))
+	}
+	return
+}
+
+// OnRequestCompleted forwards the outcome to registry-wide listeners which implement OutcomeListener.
+func (l registryListener) OnRequestCompleted(cb CircuitBreaker, outcome Outcome, slow bool) (err error) {
+	for _, listener := range l.r.listeners.Load().(CircuitBreakerListeners) {
+		if outcomeListener, ok := listener.(OutcomeListener); ok {
+			err = errors.Join(err, outcomeListener.OnRequestCompleted(cb, outcome, slow))
+		}
+	}
+	return
+}
+
+// Stop does nothing, registry-wide listeners are shared by all circuit breakers.
+func (l registryListener) Stop() {}
@@ -0,0 +1,191 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type rejectionCounter struct {
+	dummyCircuitBreakerListener
+	mutex    sync.Mutex
+	rejected map[string]int
+}
+
+func (r *rejectionCounter) OnRequestRejected(cb CircuitBreaker) (err error) {
+	r.mutex.Lock()
+	r.rejected[cb.Name().Name]++
+	r.mutex.Unlock()
+	return
+}
+
+func TestNewCircuitBreakerRegistry(t *testing.T) {
+	if _, err := NewCircuitBreakerRegistry(nil, &CircuitBreakerConfig{}); err == nil {
+		t.Fatal()
+	}
+
+	r, err := NewCircuitBreakerRegistry(nil, nil)
+	if err != nil || r.ticker != SystemTicker || r.defaultConfig.GetFailureRateThreshold() != defaultFailureRateThreshold {
+		t.Fatal(err)
+	}
+
+	if _, err = r.GetOrCreate(nil); err == nil {
+		t.Fatal()
+	}
+	if r.AddTemplate("invalid", &CircuitBreakerConfig{}) == nil || r.AddTemplate("nil", nil) == nil {
+		t.Fatal()
+	}
+	if r.Override(nil, r.defaultConfig) == nil {
+		t.Fatal()
+	}
+}
+
+func TestCircuitBreakerRegistry(t *testing.T) {
+	r, _ := NewCircuitBreakerRegistry(SystemTicker, nil)
+
+	countBased, err := NewCircuitBreakerBuilder().SetSlidingWindowType(SlidingWindowCountBased, 1).BuildConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.AddTemplate("count", countBased); err != nil {
+		t.Fatal(err)
+	}
+
+	hostA, hostB, hostC := &Name{Namespace: "svc", Name: "a"}, &Name{Namespace: "svc", Name: "b"}, &Name{Namespace: "svc", Name: "c"}
+	if err = r.Override(hostC, countBased); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := r.GetOrCreate(hostA)
+	if err != nil || *a.Name() != *hostA {
+		t.Fatal(err)
+	}
+
+	// indexed by value of Name
+	if same, _ := r.GetOrCreate(&Name{Namespace: "svc", Name: "a"}); same != a {
+		t.Fatal()
+	}
+
+	if _, err = r.GetOrCreateWithTemplate(hostB, "unknown"); err == nil {
+		t.Fatal()
+	}
+	b, _ := r.GetOrCreateWithTemplate(hostB, "count")
+	c, _ := r.GetOrCreate(hostC)
+	if b.(*NonBlockingCircuitBreaker).config.GetSlidingWindowType() != SlidingWindowCountBased ||
+		c.(*NonBlockingCircuitBreaker).config.GetSlidingWindowType() != SlidingWindowCountBased ||
+		a.(*NonBlockingCircuitBreaker).config.GetSlidingWindowType() != SlidingWindowTimeBased {
+		t.Fatal()
+	}
+
+	// registry-wide listener is notified of breakers created before it is added
+	listener := &rejectionCounter{rejected: make(map[string]int)}
+	r.AddListener(listener)
+	b.(ManualCircuitBreaker).ForceOpen()
+	c.(ManualCircuitBreaker).ForceOpen()
+	b.CanRequest()
+	b.CanRequest()
+	c.CanRequest()
+	if listener.rejected["b"] != 2 || listener.rejected["c"] != 1 {
+		t.Fatal(listener.rejected)
+	}
+
+	count := 0
+	for range r.All() {
+		count++
+	}
+	if count != 3 {
+		t.Fatal(count)
+	}
+
+	if !r.Remove(hostA) || r.Remove(hostA) {
+		t.Fatal()
+	}
+	if _, ok := r.Get(hostA); ok {
+		t.Fatal()
+	}
+	if recreated, _ := r.GetOrCreate(hostA); recreated == a {
+		t.Fatal()
+	}
+}
+
+func TestCircuitBreakerRegistry_Concurrent(t *testing.T) {
+	r, _ := NewCircuitBreakerRegistry(nil, nil)
+
+	var wg sync.WaitGroup
+	breakers := make([]CircuitBreaker, 16)
+	for i := range breakers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.AddListener(&dummyCircuitBreakerListener{})
+			breakers[i], _ = r.GetOrCreate(&Name{Name: "shared"})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, cb := range breakers {
+		if cb != breakers[0] {
+			t.Fatal()
+		}
+	}
+}
+
+// reentrantListener uses the registry when notified, and fails with err if any.
+type reentrantListener struct {
+	dummyCircuitBreakerListener
+	r   *CircuitBreakerRegistry
+	err error
+}
+
+func (l *reentrantListener) OnStateChanged(cb CircuitBreaker, state CircuitState) (err error) {
+	l.r.Get(cb.Name())
+	for range l.r.All() {
+	}
+	if cb.Name().Name == "a" {
+		_, _ = l.r.GetOrCreate(&Name{Name: "other"})
+	}
+	return l.err
+}
+
+func TestCircuitBreakerRegistry_ReentrantListener(t *testing.T) {
+	r, _ := NewCircuitBreakerRegistry(nil, nil)
+	errA, errB := errors.New("a"), errors.New("b")
+	r.AddListener(&reentrantListener{r: r, err: errA})
+	r.AddListener(&reentrantListener{r: r, err: errB})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = r.GetOrCreate(&Name{Name: "a"})
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlock")
+	}
+
+	cb, ok := r.Get(&Name{Name: "a"})
+	if _, other := r.Get(&Name{Name: "other"}); !ok || !other {
+		t.Fatal()
+	}
+
+	// errors of all listeners are kept
+	if err := (registryListener{r}).OnStateChanged(cb, CircuitStateOpen); !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatal(err)
+	}
+}
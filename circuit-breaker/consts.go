@@ -26,6 +26,10 @@ const (
 	defaultCircuitOpenWindow       = time.Duration(10 * time.Second)
 	defaultCounterSlidingWindow    = time.Duration(20 * time.Second)
 	defaultCounterUpdateInterval   = time.Duration(1 * time.Second)
+	defaultSlidingWindowSize       = 100
+	defaultSlowCallRateThreshold   = 0.8
+	defaultPermittedTrialRequests  = 1
+	defaultTrialSuccessRate        = 1.0
 )
 
 // CircuitState represents state of the circuit breaker.
@@ -36,10 +40,65 @@ const (
 	CircuitStateClosed CircuitState = 0
 	// CircuitStateOpen the circuit is tripped. All requests fail immediately without calling the remote service.
 	CircuitStateOpen CircuitState = 1
-	// CircuitStateHalfOpen only one trial request is sent at a time until at least one request succeeds or fails.
-	// If it doesn't complete within a certain time, another trial request will be sent again.
-	// All other requests fails immediately same as OPEN.
+	// CircuitStateHalfOpen only a limited number of trial requests (one by default) are sent until enough of them
+	// succeed to close the circuit, or too many of them fail. If they don't complete within a certain time,
+	// another round of trial requests will be sent again. All other requests fails immediately same as OPEN.
 	CircuitStateHalfOpen CircuitState = 2
+	// CircuitStateForcedOpen the circuit is opened manually. All requests fail immediately until the state is changed manually.
+	CircuitStateForcedOpen CircuitState = 3
+	// CircuitStateDisabled the circuit breaker is disabled manually. All requests are sent and no events are counted
+	// until the state is changed manually.
+	CircuitStateDisabled CircuitState = 4
+)
+
+// String returns the name of circuit state, e.g. HALF_OPEN.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitStateClosed:
+		return "CLOSED"
+	case CircuitStateOpen:
+		return "OPEN"
+	case CircuitStateHalfOpen:
+		return "HALF_OPEN"
+	case CircuitStateForcedOpen:
+		return "FORCED_OPEN"
+	case CircuitStateDisabled:
+		return "DISABLED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Outcome represents how a call made through Execute is recorded.
+type Outcome byte
+
+const (
+	// OutcomeSuccess the call is recorded as a success.
+	OutcomeSuccess Outcome = 0
+	// OutcomeFailure the call is recorded as a failure.
+	OutcomeFailure Outcome = 1
+	// OutcomeIgnore the call is not recorded, e.g. it was canceled by caller or failed because of a bad request.
+	OutcomeIgnore Outcome = 2
+)
+
+// SlidingWindowType represents how the circuit breaker accumulates the count of events.
+type SlidingWindowType byte
+
+const (
+	// SlidingWindowTimeBased accumulates events within the last counterSlidingWindow duration, using SlidingWindowCounter.
+	SlidingWindowTimeBased SlidingWindowType = 0
+	// SlidingWindowCountBased accumulates events within the last N calls, using CountBasedWindowCounter.
+	SlidingWindowCountBased SlidingWindowType = 1
+)
+
+// OverflowPolicy represents what AsyncListener does with an event when its buffer is full.
+type OverflowPolicy byte
+
+const (
+	// OverflowPolicyDrop discards the event, so that the circuit breaker is never blocked by slow listeners.
+	OverflowPolicyDrop OverflowPolicy = 0
+	// OverflowPolicyBlock waits until the buffer has room for the event, so that no event is lost.
+	OverflowPolicyBlock OverflowPolicy = 1
 )
 
 var (
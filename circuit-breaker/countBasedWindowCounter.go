@@ -0,0 +1,116 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CountBasedWindowCounter accumulates the count of events within the last N calls,
+// similar to resilience4j's COUNT_BASED sliding window.
+//
+// Unlike SlidingWindowCounter, the count is updated on every event, thus a breaker
+// could detect a remote service fault regardless of request rate.
+type CountBasedWindowCounter struct {
+	mutex    sync.Mutex
+	outcomes []byte // circular buffer of the last N calls, see outcomeFailure and outcomeSlow
+	head     int    // index of the oldest outcome, which is overwritten next
+	recorded int    // number of recorded outcomes, at most len(outcomes)
+	success  int64
+	failure  int64
+	slow     int64
+}
+
+const (
+	outcomeFailure byte = 1 << iota
+	outcomeSlow
+)
+
+// NewCountBasedWindowCounter creates new CountBasedWindowCounter over the last size calls.
+func NewCountBasedWindowCounter(size int) (c *CountBasedWindowCounter, e error) {
+	if size <= 0 {
+		e = fmt.Errorf("size: %d (expected: > 0)", size)
+		return
+	}
+
+	c = &CountBasedWindowCounter{
+		outcomes: make([]byte, size),
+	}
+	return
+}
+
+// Count returns the current EventCount.
+func (c *CountBasedWindowCounter) Count() *EventCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return NewEventCountWithSlow(c.success, c.failure, c.slow)
+}
+
+// OnSuccess adds success event to the window, evicting the oldest one if the window is full.
+// Returns success/failure count of the window.
+func (c *CountBasedWindowCounter) OnSuccess() *EventCount {
+	return c.onEvent(0)
+}
+
+// OnFailure adds failure event to the window, evicting the oldest one if the window is full.
+// Returns success/failure count of the window.
+func (c *CountBasedWindowCounter) OnFailure() *EventCount {
+	return c.onEvent(outcomeFailure)
+}
+
+// OnSlowSuccess adds success event of a slow call to the window, evicting the oldest one if the window is full.
+// Returns success/failure count of the window.
+func (c *CountBasedWindowCounter) OnSlowSuccess() *EventCount {
+	return c.onEvent(outcomeSlow)
+}
+
+// OnSlowFailure adds failure event of a slow call to the window, evicting the oldest one if the window is full.
+// Returns success/failure count of the window.
+func (c *CountBasedWindowCounter) OnSlowFailure() *EventCount {
+	return c.onEvent(outcomeFailure | outcomeSlow)
+}
+
+func (c *CountBasedWindowCounter) onEvent(outcome byte) *EventCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.recorded == len(c.outcomes) {
+		// evicts the oldest outcome
+		c.count(c.outcomes[c.head], -1)
+	} else {
+		c.recorded++
+	}
+
+	c.outcomes[c.head] = outcome
+	if c.head++; c.head == len(c.outcomes) {
+		c.head = 0
+	}
+	c.count(outcome, 1)
+
+	return NewEventCountWithSlow(c.success, c.failure, c.slow)
+}
+
+func (c *CountBasedWindowCounter) count(outcome byte, delta int64) {
+	if outcome&outcomeFailure != 0 {
+		c.failure += delta
+	} else {
+		c.success += delta
+	}
+
+	if outcome&outcomeSlow != 0 {
+		c.slow += delta
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewCountBasedWindowCounter(t *testing.T) {
+	if _, err := NewCountBasedWindowCounter(0); err == nil {
+		t.Fatal()
+	}
+
+	if c, err := NewCountBasedWindowCounter(3); err != nil || c.Count().Total() != 0 {
+		t.Fatal(err)
+	}
+}
+
+func TestCountBasedWindowCounter_Evict(t *testing.T) {
+	c, _ := NewCountBasedWindowCounter(3)
+
+	if e := c.OnFailure(); e.Failure() != 1 || e.Total() != 1 {
+		t.Fatal(e)
+	}
+	c.OnSuccess()
+	c.OnFailure()
+
+	// evicts the first failure
+	if e := c.OnSuccess(); e.Success() != 2 || e.Failure() != 1 {
+		t.Fatal(e)
+	}
+
+	// evicts the first success and the second failure
+	c.OnSuccess()
+	if e := c.OnSuccess(); e.Success() != 3 || e.Failure() != 0 {
+		t.Fatal(e)
+	}
+
+	if e := c.Count(); e.Success() != 3 || e.Total() != 3 {
+		t.Fatal(e)
+	}
+}
+
+func TestCountBasedWindowCounter_Concurrent(t *testing.T) {
+	c, _ := NewCountBasedWindowCounter(100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if i%2 == 0 {
+					c.OnSuccess()
+				} else {
+					c.OnFailure()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if e := c.Count(); e.Total() != 100 {
+		t.Fatal(e)
+	}
+}
+
+func TestCountBasedCircuitBreaker(t *testing.T) {
+	cb, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 5).
+		SetFailureRateThreshold(0.5).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// minimumRequestThreshold is capped by window size, so 5 calls are enough
+	cb.OnSuccess()
+	for i := 0; i < 3; i++ {
+		cb.OnFailure()
+		if !cb.CanRequest() {
+			t.Fatal(i)
+		}
+	}
+
+	cb.OnFailure()
+	if cb.CanRequest() {
+		t.Fatal()
+	}
+}
+
+func TestCountBasedWindowCounter_Slow(t *testing.T) {
+	c, _ := NewCountBasedWindowCounter(2)
+
+	if e := c.OnSlowFailure(); e.Slow() != 1 || e.Failure() != 1 {
+		t.Fatal(e)
+	}
+
+	c.OnSuccess()
+	if e := c.OnSlowSuccess(); e.Slow() != 1 || e.Success() != 2 || e.Failure() != 0 {
+		t.Fatal(e)
+	}
+
+	// the slow success is evicted after two more calls
+	if e := c.OnSuccess(); e.Slow() != 1 {
+		t.Fatal(e)
+	}
+	if e := c.OnSuccess(); e.Slow() != 0 || e.Total() != 2 {
+		t.Fatal(e)
+	}
+}
+
+func TestCountBasedWindowCounter_ConcurrentSlow(t *testing.T) {
+	c, _ := NewCountBasedWindowCounter(4000)
+
+	// slowness is never attributed to a call of another goroutine
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(slow bool) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if slow {
+					c.OnSlowFailure()
+				} else {
+					c.OnSuccess()
+				}
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	if e := c.Count(); e.Slow() != 2000 || e.Failure() != 2000 || e.Success() != 2000 {
+		t.Fatal(e)
+	}
+}
+
+func TestSlowCallCircuitBreaker(t *testing.T) {
+	cb, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 4).
+		SetSlowCallDurationThreshold(5 * time.Millisecond).
+		SetSlowCallRateThreshold(0.5).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	execute := func(d time.Duration) error {
+		_, err := cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+			time.Sleep(d)
+			return nil, nil
+		})
+		return err
+	}
+
+	for _, d := range []time.Duration{0, 10 * time.Millisecond, 0, 10 * time.Millisecond} {
+		if err := execute(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 3 slow calls out of 4 exceed the threshold
+	if err := execute(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute(0); !errors.Is(err, ErrFailFast) {
+		t.Fatal(err)
+	}
+}
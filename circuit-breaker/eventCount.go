@@ -23,10 +23,21 @@ type EventCounter interface {
 	OnFailure() *EventCount
 }
 
+// SlowCallCounter is an EventCounter which also counts slow call events.
+// Slow calls are not counted by counters which do not implement it.
+type SlowCallCounter interface {
+	EventCounter
+	// OnSlowSuccess count success events of slow calls, which are counted as both success and slow at once.
+	OnSlowSuccess() *EventCount
+	// OnSlowFailure count failure events of slow calls, which are counted as both failure and slow at once.
+	OnSlowFailure() *EventCount
+}
+
 // EventCount stores the count of events.
 type EventCount struct {
 	success int64
 	failure int64
+	slow    int64
 }
 
 // NewEventCount creates new event count.
@@ -34,6 +45,11 @@ func NewEventCount(success, failure int64) *EventCount {
 	return &EventCount{success: success, failure: failure}
 }
 
+// NewEventCountWithSlow creates new event count, including the number of slow calls.
+func NewEventCountWithSlow(success, failure, slow int64) *EventCount {
+	return &EventCount{success: success, failure: failure, slow: slow}
+}
+
 // Success returns number of success events.
 func (e *EventCount) Success() int64 {
 	return e.success
@@ -44,6 +60,11 @@ func (e *EventCount) Failure() int64 {
 	return e.failure
 }
 
+// Slow returns number of slow call events.
+func (e *EventCount) Slow() int64 {
+	return e.slow
+}
+
 // Total returns total number of events.
 func (e *EventCount) Total() int64 {
 	return e.success + e.failure
@@ -67,5 +88,17 @@ func (e *EventCount) FailureRate() float64 {
 	return float64(e.failure) / float64(total)
 }
 
+// SlowRate return number of slow call rate, capped at 1.
+func (e *EventCount) SlowRate() float64 {
+	total := e.Total()
+	if total == 0 {
+		return -1
+	}
+	if e.slow >= total {
+		return 1
+	}
+	return float64(e.slow) / float64(total)
+}
+
 // EventCountZero event count with zero
 var EventCountZero = NewEventCount(0, 0)
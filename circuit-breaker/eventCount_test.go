@@ -34,3 +34,44 @@ func TestEventCount(t *testing.T) {
 		t.Errorf("Fail to return rate")
 	}
 }
+
+func TestEventCountSlow(t *testing.T) {
+	if ev := NewEventCountWithSlow(0, 0, 1); ev.SlowRate() != -1 {
+		t.Errorf("Fail to catch trivial case of slow rate")
+	}
+
+	if ev := NewEventCountWithSlow(3, 1, 1); ev.Slow() != 1 || ev.Total() != 4 || ev.SlowRate() != 0.25 {
+		t.Errorf("Fail to return slow rate")
+	}
+
+	// slow calls reported before their outcomes
+	if ev := NewEventCountWithSlow(1, 0, 2); ev.SlowRate() != 1 {
+		t.Errorf("Fail to cap slow rate")
+	}
+}
+
+func TestSlowCallCounter(t *testing.T) {
+	var counters []EventCounter
+	counters = append(counters, &SlidingWindowCounter{}, &CountBasedWindowCounter{})
+	for _, c := range counters {
+		if _, ok := c.(SlowCallCounter); !ok {
+			t.Errorf("%T must count slow calls", c)
+		}
+	}
+
+	// counters without slow events are still usable, ignoring slow calls
+	if _, ok := EventCounter(noOpCounter).(SlowCallCounter); ok {
+		t.FailNow()
+	}
+	if countSuccess(noOpCounter, true) != nil || countFailure(noOpCounter, true) != nil {
+		t.FailNow()
+	}
+
+	c, _ := NewCountBasedWindowCounter(2)
+	if e := countSuccess(c, true); e.Slow() != 1 || e.Success() != 1 {
+		t.Fatal(e)
+	}
+	if e := countFailure(c, false); e.Slow() != 1 || e.Failure() != 1 {
+		t.Fatal(e)
+	}
+}
@@ -14,16 +14,133 @@
 
 package cbreaker
 
-// Logger interface.
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// Logger interface. Prefer a *slog.Logger set per circuit breaker by CircuitBreakerBuilder.SetLogger,
+// a Logger could still be used as such through NewLoggerHandler.
 type Logger interface {
 	Info(i string)
 	Warn(title string, v interface{})
 	Error(title string, v interface{})
 }
 
-var logger Logger
+var slogLogger *slog.Logger // writes to the default logger, built once by SetDefaultLogger
 
-// SetDefaultLogger sets default logger.
+// SetDefaultLogger sets default logger, which is used by circuit breakers without their own *slog.Logger.
+// The logger is adapted by NewLoggerHandler. Setting nil disables the default logger.
 func SetDefaultLogger(lg Logger) {
-	logger = lg
+	if lg == nil {
+		slogLogger = nil
+	} else {
+		slogLogger = slog.New(NewLoggerHandler(lg))
+	}
+}
+
+// defaultLogger returns a *slog.Logger writing to the default logger, or nil if it is not set.
+func defaultLogger() *slog.Logger {
+	return slogLogger
+}
+
+// loggerHandler is a slog.Handler writing records to a Logger.
+type loggerHandler struct {
+	logger Logger
+	attrs  string // preformatted attributes
+	group  string // prefix of attribute keys
+}
+
+// NewLoggerHandler creates a slog.Handler writing records to given Logger, which adapts the Logger
+// to CircuitBreakerBuilder.SetLogHandler. Attributes are formatted as key:value separated by space.
+// Records at INFO level or below are written by Info, with the attributes appended to the message.
+// Records at WARN and ERROR level are written by Warn and Error respectively, with the message as title
+// and the attributes as value. If the only attribute is an error, e.g. returned by a listener, the error itself
+// is the value.
+func NewLoggerHandler(logger Logger) slog.Handler {
+	return &loggerHandler{logger: logger}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *loggerHandler) Enabled(context.Context, slog.Level) bool {
+	return h.logger != nil
+}
+
+// Handle writes the record to the Logger.
+func (h *loggerHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&sb, h.group, a)
+		return true
+	})
+	attrs := strings.TrimPrefix(sb.String(), " ")
+
+	switch {
+	case r.Level >= slog.LevelError:
+		h.logger.Error(r.Message, h.valueOf(r, attrs))
+	case r.Level >= slog.LevelWarn:
+		h.logger.Warn(r.Message, h.valueOf(r, attrs))
+	case attrs != "":
+		h.logger.Info(r.Message + " " + attrs)
+	default:
+		h.logger.Info(r.Message)
+	}
+	return nil
+}
+
+// valueOf returns the error of record if it is the only attribute, otherwise formatted attributes.
+func (h *loggerHandler) valueOf(r slog.Record, attrs string) (v interface{}) {
+	v = attrs
+	if h.attrs == "" && r.NumAttrs() == 1 {
+		r.Attrs(func(a slog.Attr) bool {
+			if err, ok := a.Value.Resolve().Any().(error); ok {
+				v = err
+			}
+			return false
+		})
+	}
+	return
+}
+
+// WithAttrs returns a new handler which formats given attributes in addition to the handler's attributes.
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var sb strings.Builder
+	sb.WriteString(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&sb, h.group, a)
+	}
+	return &loggerHandler{logger: h.logger, attrs: sb.String(), group: h.group}
+}
+
+// WithGroup returns a new handler which qualifies keys of subsequent attributes by given group name.
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+func appendAttr(sb *strings.Builder, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(sb, group, ga)
+		}
+		return
+	}
+
+	sb.WriteByte(' ')
+	sb.WriteString(group)
+	sb.WriteString(a.Key)
+	sb.WriteByte(':')
+	sb.WriteString(a.Value.String())
 }
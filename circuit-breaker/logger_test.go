@@ -15,7 +15,11 @@
 package cbreaker
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -25,6 +29,8 @@ var loggedWarn string
 
 var loggedError string
 
+var loggedWarnValue interface{}
+
 type fakeLogger struct{}
 
 func (f *fakeLogger) Info(i string) {
@@ -33,6 +39,7 @@ func (f *fakeLogger) Info(i string) {
 
 func (f *fakeLogger) Warn(title string, v interface{}) {
 	loggedWarn = title + fmt.Sprintf("_%v", v)
+	loggedWarnValue = v
 }
 
 func (f *fakeLogger) Error(title string, v interface{}) {
@@ -42,19 +49,121 @@ func (f *fakeLogger) Error(title string, v interface{}) {
 func TestSetLogger(t *testing.T) {
 	SetDefaultLogger(&fakeLogger{})
 
-	if logger.Info("info"); loggedInfo != "info" {
+	if defaultLogger() == nil || defaultLogger() != defaultLogger() {
 		t.FailNow()
 	}
 
-	if logger.Warn("warn", "test"); loggedWarn != "warn_test" {
+	if defaultLogger().Info("info"); loggedInfo != "info" {
 		t.FailNow()
 	}
 
-	if logger.Error("error", "test"); loggedError != "error_test" {
+	if defaultLogger().Warn("warn", "error", fmt.Errorf("test")); loggedWarn != "warn_test" {
 		t.FailNow()
 	}
 
-	if SetDefaultLogger(nil); logger != nil {
+	if defaultLogger().Error("error", "error", fmt.Errorf("test")); loggedError != "error_test" {
 		t.FailNow()
 	}
+
+	if SetDefaultLogger(nil); defaultLogger() != nil {
+		t.FailNow()
+	}
+}
+
+func TestLoggerHandler(t *testing.T) {
+	lg := slog.New(NewLoggerHandler(&fakeLogger{}))
+
+	if lg.Info("info"); loggedInfo != "info" {
+		t.Fatal(loggedInfo)
+	}
+
+	lg = lg.With("a", 1).WithGroup("g").With(slog.Group("h", "b", true))
+	if lg.Info("info", "c", "x", slog.Group("", "d", 2.5), slog.Attr{}); loggedInfo != "info a:1 g.h.b:true g.c:x g.d:2.5" {
+		t.Fatal(loggedInfo)
+	}
+
+	if lg.WithGroup("").Warn("warn", "error", fmt.Errorf("test")); loggedWarn != "warn_a:1 g.h.b:true g.error:test" {
+		t.Fatal(loggedWarn)
+	}
+
+	// the error is passed through if it is the only attribute
+	fakeErr := fmt.Errorf("test")
+	if slog.New(NewLoggerHandler(&fakeLogger{})).Warn("warn", "error", fakeErr); loggedWarnValue != fakeErr {
+		t.Fatal(loggedWarnValue)
+	}
+	if slog.New(NewLoggerHandler(&fakeLogger{})).Warn("warn", "error", fakeErr, "a", 1); loggedWarnValue != "error:test a:1" {
+		t.Fatal(loggedWarnValue)
+	}
+
+	if lg.Error("error"); loggedError != "error_a:1 g.h.b:true" {
+		t.Fatal(loggedError)
+	}
+
+	if NewLoggerHandler(nil).Enabled(context.Background(), slog.LevelError) {
+		t.Fatal()
+	}
+}
+
+func TestCircuitBreakerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	if config, _ := NewCircuitBreakerBuilder().SetLogHandler(handler).SetLogHandler(nil).BuildConfig(); config.GetLogger() != nil {
+		t.Fatal()
+	}
+
+	cb, err := NewCircuitBreakerBuilder().
+		Name(&Name{Namespace: "ns", Subsystem: "sub", Name: "a"}).
+		SetSlidingWindowType(SlidingWindowCountBased, 2).
+		SetMinimumRequestThreshold(2).
+		SetLogHandler(handler).
+		AddListener(&cbListenerMock{}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb.OnFailure()
+	cb.OnFailure()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		`level=INFO msg="Circuit state changed" namespace=ns subsystem=sub name=a to=CLOSED`,
+		`level=WARN msg="An error occurred when notifying a StateChanged event" error="Fake error"`,
+		`level=WARN msg="An error occurred when notifying an EventCountUpdated event" error="Fake error"`,
+		`level=WARN msg="An error occurred when notifying an EventCountUpdated event" error="Fake error"`,
+		`level=INFO msg="Circuit state changed" namespace=ns subsystem=sub name=a from=CLOSED to=OPEN failure=2 slow=0 total=2 failureRate=1`,
+	}
+	if len(lines) < len(expected) {
+		t.Fatal(buf.String())
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("%q, expected %q", lines[i], line)
+		}
+	}
+
+	// the default logger is not used
+	loggedInfo = ""
+	SetDefaultLogger(&fakeLogger{})
+	defer SetDefaultLogger(nil)
+	if cb.(ManualCircuitBreaker).Reset(); loggedInfo != "" {
+		t.Fatal(loggedInfo)
+	}
+
+	// errors of listeners are passed to the default logger as is
+	loggedWarnValue = nil
+	if _, err = NewCircuitBreakerBuilder().AddListener(&cbListenerMock{}).Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err, ok := loggedWarnValue.(error); !ok || err.Error() != "Fake error" {
+		t.Fatal(loggedWarnValue)
+	}
 }
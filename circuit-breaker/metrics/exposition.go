@@ -0,0 +1,146 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	cbreaker "go.linecorp.com/garr/circuit-breaker"
+)
+
+// ContentType of Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// StateLabelValue returns the value of state label for given circuit state, e.g. half_open.
+func StateLabelValue(state cbreaker.CircuitState) string {
+	return strings.ToLower(state.String())
+}
+
+// WriteTo renders the metrics of all circuit breakers to w in Prometheus text exposition format.
+func (l *Listener) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	snapshots := l.Snapshots()
+	if len(snapshots) > 0 {
+		writeFamily(bw, StateMetric, StateHelp, "gauge")
+		for i := range snapshots {
+			for _, state := range CircuitStates {
+				value := int64(0)
+				if snapshots[i].State == state {
+					value = 1
+				}
+				writeSample(bw, StateMetric, &snapshots[i].Name, StateLabel, StateLabelValue(state), value)
+			}
+		}
+
+		writeFamily(bw, RequestsMetric, RequestsHelp, "gauge")
+		for i := range snapshots {
+			writeSample(bw, RequestsMetric, &snapshots[i].Name, ResultLabel, ResultSuccess, snapshots[i].Success)
+			writeSample(bw, RequestsMetric, &snapshots[i].Name, ResultLabel, ResultFailure, snapshots[i].Failure)
+		}
+
+		writeFamily(bw, CompletedRequestsMetric, CompletedRequestsHelp, "counter")
+		for i := range snapshots {
+			writeSample(bw, CompletedRequestsMetric, &snapshots[i].Name, ResultLabel, ResultSuccess, snapshots[i].SuccessTotal)
+			writeSample(bw, CompletedRequestsMetric, &snapshots[i].Name, ResultLabel, ResultFailure, snapshots[i].FailureTotal)
+		}
+
+		writeFamily(bw, SlowRequestsMetric, SlowRequestsHelp, "counter")
+		for i := range snapshots {
+			writeSample(bw, SlowRequestsMetric, &snapshots[i].Name, "", "", snapshots[i].SlowTotal)
+		}
+
+		writeFamily(bw, RejectedRequestsMetric, RejectedRequestsHelp, "counter")
+		for i := range snapshots {
+			writeSample(bw, RejectedRequestsMetric, &snapshots[i].Name, "", "", snapshots[i].Rejected)
+		}
+
+		writeFamily(bw, TransitionsMetric, TransitionsHelp, "counter")
+		for i := range snapshots {
+			for _, state := range CircuitStates {
+				writeSample(bw, TransitionsMetric, &snapshots[i].Name, StateLabel, StateLabelValue(state), snapshots[i].Transitions[state])
+			}
+		}
+	}
+
+	err = bw.Flush()
+	n = cw.n
+	return
+}
+
+// ServeHTTP serves the metrics of all circuit breakers in Prometheus text exposition format.
+func (l *Listener) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = l.WriteTo(w)
+}
+
+func writeFamily(w *bufio.Writer, metric, help, typ string) {
+	_, _ = w.WriteString("# HELP ")
+	_, _ = w.WriteString(metric)
+	_ = w.WriteByte(' ')
+	_, _ = helpEscaper.WriteString(w, help)
+	_, _ = w.WriteString("\n# TYPE ")
+	_, _ = w.WriteString(metric)
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(typ)
+	_ = w.WriteByte('\n')
+}
+
+// writeSample writes a sample labeled by name, and by extra label if it is not empty.
+func writeSample(w *bufio.Writer, metric string, name *cbreaker.Name, extraLabel, extraValue string, value int64) {
+	_, _ = w.WriteString(metric)
+	_ = w.WriteByte('{')
+	writeLabel(w, NamespaceLabel, name.Namespace)
+	_ = w.WriteByte(',')
+	writeLabel(w, SubsystemLabel, name.Subsystem)
+	_ = w.WriteByte(',')
+	writeLabel(w, NameLabel, name.Name)
+	if extraLabel != "" {
+		_ = w.WriteByte(',')
+		writeLabel(w, extraLabel, extraValue)
+	}
+	_, _ = w.WriteString("} ")
+	_, _ = w.WriteString(strconv.FormatInt(value, 10))
+	_ = w.WriteByte('\n')
+}
+
+func writeLabel(w *bufio.Writer, label, value string) {
+	_, _ = w.WriteString(label)
+	_, _ = w.WriteString(`="`)
+	_, _ = labelValueEscaper.WriteString(w, value)
+	_ = w.WriteByte('"')
+}
+
+// countingWriter counts the bytes written to underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = c.w.Write(p)
+	c.n += int64(n)
+	return
+}
@@ -0,0 +1,224 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package metrics provides a CircuitBreakerListener which keeps track of circuit breaker metrics
+// and renders them in Prometheus text exposition format, without depending on the Prometheus client.
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	ga "go.linecorp.com/garr/adder"
+	cbreaker "go.linecorp.com/garr/circuit-breaker"
+)
+
+// Metric names.
+const (
+	// StateMetric is a gauge per circuit state, which is 1 for the current state of the circuit breaker and 0 for the others.
+	StateMetric = "circuit_breaker_state"
+	// RequestsMetric is a gauge of the number of requests, by result, in the current sliding window of the circuit breaker.
+	RequestsMetric = "circuit_breaker_requests"
+	// CompletedRequestsMetric is a counter of the requests completed through the circuit breaker, by result.
+	// Unlike RequestsMetric, it is monotonic and counts requests in any circuit state.
+	CompletedRequestsMetric = "circuit_breaker_completed_requests_total"
+	// SlowRequestsMetric is a counter of the slow requests completed through the circuit breaker.
+	// Slow requests are also counted by CompletedRequestsMetric as either success or failure.
+	SlowRequestsMetric = "circuit_breaker_slow_requests_total"
+	// RejectedRequestsMetric is a counter of the requests rejected by the circuit breaker.
+	RejectedRequestsMetric = "circuit_breaker_rejected_requests_total"
+	// TransitionsMetric is a counter of the transitions of the circuit breaker, by the state entered.
+	TransitionsMetric = "circuit_breaker_transitions_total"
+)
+
+// Metric help texts.
+const (
+	StateHelp             = "Current state of the circuit breaker, 1 for the current state and 0 for the others."
+	RequestsHelp          = "Number of requests in the current sliding window of the circuit breaker."
+	CompletedRequestsHelp = "Total number of requests completed through the circuit breaker."
+	SlowRequestsHelp      = "Total number of slow requests completed through the circuit breaker."
+	RejectedRequestsHelp  = "Total number of requests rejected by the circuit breaker."
+	TransitionsHelp       = "Total number of transitions of the circuit breaker to the state."
+)
+
+// Label names. A circuit breaker Name maps onto namespace, subsystem and name labels.
+const (
+	NamespaceLabel = "namespace"
+	SubsystemLabel = "subsystem"
+	NameLabel      = "name"
+	StateLabel     = "state"
+	ResultLabel    = "result"
+)
+
+// Values of the result label of RequestsMetric and CompletedRequestsMetric.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// CircuitStates lists all circuit states, in the order they are exported.
+var CircuitStates = []cbreaker.CircuitState{
+	cbreaker.CircuitStateClosed,
+	cbreaker.CircuitStateOpen,
+	cbreaker.CircuitStateHalfOpen,
+	cbreaker.CircuitStateForcedOpen,
+	cbreaker.CircuitStateDisabled,
+}
+
+// Snapshot holds the metrics of a circuit breaker at a point in time.
+type Snapshot struct {
+	Name         cbreaker.Name
+	State        cbreaker.CircuitState
+	Success      int64 // in the current sliding window
+	Failure      int64 // in the current sliding window
+	Slow         int64 // in the current sliding window
+	SuccessTotal int64
+	FailureTotal int64
+	SlowTotal    int64
+	Rejected     int64
+	Transitions  map[cbreaker.CircuitState]int64
+}
+
+// series tracks the metrics of a circuit breaker.
+type series struct {
+	name        cbreaker.Name
+	state       atomic.Uint32
+	count       atomic.Pointer[cbreaker.EventCount]
+	successes   ga.JDKAdder
+	failures    ga.JDKAdder
+	slows       ga.JDKAdder
+	rejected    ga.JDKAdder
+	transitions [cbreaker.CircuitStateDisabled + 1]ga.JDKAdder // indexed by the state entered
+}
+
+func newSeries(name cbreaker.Name) *series {
+	s := &series{name: name}
+	s.count.Store(cbreaker.EventCountZero)
+	return s
+}
+
+func (s *series) snapshot() Snapshot {
+	count := s.count.Load()
+	snapshot := Snapshot{
+		Name:         s.name,
+		State:        cbreaker.CircuitState(s.state.Load()),
+		Success:      count.Success(),
+		Failure:      count.Failure(),
+		Slow:         count.Slow(),
+		SuccessTotal: s.successes.Sum(),
+		FailureTotal: s.failures.Sum(),
+		SlowTotal:    s.slows.Sum(),
+		Rejected:     s.rejected.Sum(),
+		Transitions:  make(map[cbreaker.CircuitState]int64, len(CircuitStates)),
+	}
+	for _, state := range CircuitStates {
+		snapshot.Transitions[state] = s.transitions[state].Sum()
+	}
+	return snapshot
+}
+
+// Listener is an OutcomeListener which keeps track of the metrics of every circuit breaker it listens to,
+// keyed by Name. It can be shared by many circuit breakers, e.g. added to a CircuitBreakerRegistry.
+//
+// Listener is safe for concurrent use.
+type Listener struct {
+	series sync.Map // cbreaker.Name -> *series
+}
+
+// NewListener creates new Listener.
+func NewListener() *Listener {
+	return &Listener{}
+}
+
+func (l *Listener) seriesOf(cb cbreaker.CircuitBreaker) *series {
+	var name cbreaker.Name
+	if n := cb.Name(); n != nil {
+		name = *n
+	}
+
+	if s, ok := l.series.Load(name); ok {
+		return s.(*series)
+	}
+	s, _ := l.series.LoadOrStore(name, newSeries(name))
+	return s.(*series)
+}
+
+// OnStateChanged records the state of the circuit breaker and counts the transition.
+func (l *Listener) OnStateChanged(cb cbreaker.CircuitBreaker, state cbreaker.CircuitState) (err error) {
+	s := l.seriesOf(cb)
+	s.state.Store(uint32(state))
+	if int(state) < len(s.transitions) {
+		s.transitions[state].Inc()
+	}
+	return
+}
+
+// OnEventCountUpdated records the event count in the current sliding window of the circuit breaker.
+func (l *Listener) OnEventCountUpdated(cb cbreaker.CircuitBreaker, eventCount *cbreaker.EventCount) (err error) {
+	if eventCount != nil {
+		l.seriesOf(cb).count.Store(eventCount)
+	}
+	return
+}
+
+// OnRequestCompleted counts the completed request by result.
+func (l *Listener) OnRequestCompleted(cb cbreaker.CircuitBreaker, outcome cbreaker.Outcome, slow bool) (err error) {
+	s := l.seriesOf(cb)
+	switch outcome {
+	case cbreaker.OutcomeSuccess:
+		s.successes.Inc()
+	case cbreaker.OutcomeFailure:
+		s.failures.Inc()
+	}
+	if slow {
+		s.slows.Inc()
+	}
+	return
+}
+
+// OnRequestRejected counts the rejected request.
+func (l *Listener) OnRequestRejected(cb cbreaker.CircuitBreaker) (err error) {
+	l.seriesOf(cb).rejected.Inc()
+	return
+}
+
+// Stop does nothing. Recorded metrics are kept until removed by Remove.
+func (l *Listener) Stop() {}
+
+// Remove drops the metrics of the circuit breaker with given name, e.g. after removing it from a registry.
+func (l *Listener) Remove(name *cbreaker.Name) {
+	if name != nil {
+		l.series.Delete(*name)
+	}
+}
+
+// Snapshots returns the metrics of all circuit breakers, sorted by Name.
+func (l *Listener) Snapshots() (snapshots []Snapshot) {
+	l.series.Range(func(_, s interface{}) bool {
+		snapshots = append(snapshots, s.(*series).snapshot())
+		return true
+	})
+	sort.Slice(snapshots, func(i, j int) bool {
+		a, b := snapshots[i].Name, snapshots[j].Name
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Subsystem != b.Subsystem {
+			return a.Subsystem < b.Subsystem
+		}
+		return a.Name < b.Name
+	})
+	return
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cbreaker "go.linecorp.com/garr/circuit-breaker"
+)
+
+func newCircuitBreaker(t *testing.T, l *Listener, name *cbreaker.Name) cbreaker.ManualCircuitBreaker {
+	cb, err := cbreaker.NewCircuitBreakerBuilder().
+		Name(name).
+		SetSlidingWindowType(cbreaker.SlidingWindowCountBased, 2).
+		SetFailureRateThreshold(0.5).
+		SetMinimumRequestThreshold(2).
+		AddListener(l).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cb.(cbreaker.ManualCircuitBreaker)
+}
+
+func TestListener(t *testing.T) {
+	l := NewListener()
+	b := newCircuitBreaker(t, l, &cbreaker.Name{Namespace: "ns", Subsystem: "sub", Name: "b"})
+	a := newCircuitBreaker(t, l, &cbreaker.Name{Namespace: "ns", Subsystem: "sub", Name: "a"})
+
+	a.OnSuccess()
+	b.OnFailure()
+	b.OnFailure()
+	if b.State() != cbreaker.CircuitStateOpen || b.CanRequest() || b.CanRequest() {
+		t.Fatal(b.State())
+	}
+
+	snapshots := l.Snapshots()
+	if len(snapshots) != 2 || snapshots[0].Name.Name != "a" || snapshots[1].Name.Name != "b" {
+		t.Fatal(snapshots)
+	}
+
+	if s := snapshots[0]; s.State != cbreaker.CircuitStateClosed || s.Success != 1 || s.Failure != 0 || s.Rejected != 0 ||
+		s.SuccessTotal != 1 || s.FailureTotal != 0 ||
+		s.Transitions[cbreaker.CircuitStateClosed] != 1 || s.Transitions[cbreaker.CircuitStateOpen] != 0 {
+		t.Fatal(s)
+	}
+
+	// the window count is reset on transition, unlike totals
+	if s := snapshots[1]; s.State != cbreaker.CircuitStateOpen || s.Success != 0 || s.Failure != 0 || s.Rejected != 2 ||
+		s.SuccessTotal != 0 || s.FailureTotal != 2 ||
+		s.Transitions[cbreaker.CircuitStateClosed] != 1 || s.Transitions[cbreaker.CircuitStateOpen] != 1 {
+		t.Fatal(s)
+	}
+
+	l.Remove(b.Name())
+	l.Remove(nil)
+	if snapshots = l.Snapshots(); len(snapshots) != 1 || snapshots[0].Name.Name != "a" {
+		t.Fatal(snapshots)
+	}
+}
+
+func TestListener_WriteTo(t *testing.T) {
+	l := NewListener()
+
+	var sb strings.Builder
+	if n, err := l.WriteTo(&sb); err != nil || n != 0 || sb.Len() != 0 {
+		t.Fatal(n, err)
+	}
+
+	cb := newCircuitBreaker(t, l, &cbreaker.Name{Namespace: "ns", Name: "a\"b\\c\n"})
+	cb.OnFailure()
+	cb.ForceOpen()
+	cb.CanRequest()
+	cb.OnSuccess() // counted in total only, e.g. a request sent before FORCED_OPEN
+	_ = l.OnRequestCompleted(cb, cbreaker.OutcomeSuccess, true)
+
+	n, err := l.WriteTo(&sb)
+	if err != nil || n != int64(sb.Len()) {
+		t.Fatal(n, err)
+	}
+
+	labels := `namespace="ns",subsystem="",name="a\"b\\c\n"`
+	expected := []string{
+		"# HELP " + StateMetric + " " + StateHelp,
+		"# TYPE " + StateMetric + " gauge",
+		StateMetric + "{" + labels + `,state="closed"} 0`,
+		StateMetric + "{" + labels + `,state="forced_open"} 1`,
+		"# TYPE " + RequestsMetric + " gauge",
+		RequestsMetric + "{" + labels + `,result="failure"} 0`,
+		"# TYPE " + CompletedRequestsMetric + " counter",
+		CompletedRequestsMetric + "{" + labels + `,result="success"} 2`,
+		CompletedRequestsMetric + "{" + labels + `,result="failure"} 1`,
+		"# TYPE " + SlowRequestsMetric + " counter",
+		SlowRequestsMetric + "{" + labels + "} 1",
+		"# TYPE " + RejectedRequestsMetric + " counter",
+		RejectedRequestsMetric + "{" + labels + "} 1",
+		"# TYPE " + TransitionsMetric + " counter",
+		TransitionsMetric + "{" + labels + `,state="closed"} 1`,
+		TransitionsMetric + "{" + labels + `,state="forced_open"} 1`,
+		TransitionsMetric + "{" + labels + `,state="half_open"} 0`,
+	}
+	lines := strings.Split(sb.String(), "\n")
+	for _, line := range expected {
+		if !contains(lines, line) {
+			t.Errorf("missing line %q in:\n%s", line, sb.String())
+		}
+	}
+	if len(lines) != 6*2+5+2+2+1+1+5+1 {
+		t.Fatal(len(lines))
+	}
+}
+
+func TestListener_ServeHTTP(t *testing.T) {
+	l := NewListener()
+	newCircuitBreaker(t, l, &cbreaker.Name{Name: "a"})
+
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Header().Get("Content-Type") != ContentType ||
+		!strings.Contains(w.Body.String(), StateMetric+`{namespace="",subsystem="",name="a",state="closed"} 1`) {
+		t.Fatal(w.Header(), w.Body.String())
+	}
+}
+
+func TestListener_Registry(t *testing.T) {
+	l := NewListener()
+	registry, err := cbreaker.NewCircuitBreakerRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.AddListener(l)
+
+	cb, err := registry.GetOrCreate(&cbreaker.Name{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		cb.OnSuccess()
+	}
+	cb.OnFailure()
+
+	// outcomes are forwarded by the registry
+	if snapshots := l.Snapshots(); len(snapshots) != 1 || snapshots[0].SuccessTotal != 5 || snapshots[0].FailureTotal != 1 {
+		t.Fatal(snapshots)
+	}
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
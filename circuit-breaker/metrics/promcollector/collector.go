@@ -0,0 +1,94 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package promcollector exposes circuit breaker metrics through the prometheus.Collector interface.
+package promcollector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.linecorp.com/garr/circuit-breaker/metrics"
+)
+
+var nameLabels = []string{metrics.NamespaceLabel, metrics.SubsystemLabel, metrics.NameLabel}
+
+// Collector is a CircuitBreakerListener which is also a prometheus.Collector, exporting the metrics
+// of every circuit breaker it listens to. See metrics.Listener for the recorded metrics.
+type Collector struct {
+	*metrics.Listener
+
+	state       *prometheus.Desc
+	requests    *prometheus.Desc
+	completed   *prometheus.Desc
+	slow        *prometheus.Desc
+	rejected    *prometheus.Desc
+	transitions *prometheus.Desc
+}
+
+// NewCollector creates new Collector.
+func NewCollector() *Collector {
+	return NewCollectorOf(metrics.NewListener())
+}
+
+// NewCollectorOf creates new Collector exporting the metrics recorded by given listener.
+func NewCollectorOf(listener *metrics.Listener) *Collector {
+	return &Collector{
+		Listener:    listener,
+		state:       newDesc(metrics.StateMetric, metrics.StateHelp, metrics.StateLabel),
+		requests:    newDesc(metrics.RequestsMetric, metrics.RequestsHelp, metrics.ResultLabel),
+		completed:   newDesc(metrics.CompletedRequestsMetric, metrics.CompletedRequestsHelp, metrics.ResultLabel),
+		slow:        newDesc(metrics.SlowRequestsMetric, metrics.SlowRequestsHelp),
+		rejected:    newDesc(metrics.RejectedRequestsMetric, metrics.RejectedRequestsHelp),
+		transitions: newDesc(metrics.TransitionsMetric, metrics.TransitionsHelp, metrics.StateLabel),
+	}
+}
+
+func newDesc(metric, help string, extraLabels ...string) *prometheus.Desc {
+	labels := append(append(make([]string, 0, len(nameLabels)+len(extraLabels)), nameLabels...), extraLabels...)
+	return prometheus.NewDesc(metric, help, labels, nil)
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.state
+	ch <- c.requests
+	ch <- c.completed
+	ch <- c.slow
+	ch <- c.rejected
+	ch <- c.transitions
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.Snapshots() {
+		ns, sub, name := s.Name.Namespace, s.Name.Subsystem, s.Name.Name
+
+		for _, state := range metrics.CircuitStates {
+			value := 0.0
+			if s.State == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, value, ns, sub, name, metrics.StateLabelValue(state))
+			ch <- prometheus.MustNewConstMetric(c.transitions, prometheus.CounterValue, float64(s.Transitions[state]),
+				ns, sub, name, metrics.StateLabelValue(state))
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.GaugeValue, float64(s.Success), ns, sub, name, metrics.ResultSuccess)
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.GaugeValue, float64(s.Failure), ns, sub, name, metrics.ResultFailure)
+		ch <- prometheus.MustNewConstMetric(c.completed, prometheus.CounterValue, float64(s.SuccessTotal), ns, sub, name, metrics.ResultSuccess)
+		ch <- prometheus.MustNewConstMetric(c.completed, prometheus.CounterValue, float64(s.FailureTotal), ns, sub, name, metrics.ResultFailure)
+		ch <- prometheus.MustNewConstMetric(c.slow, prometheus.CounterValue, float64(s.SlowTotal), ns, sub, name)
+		ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(s.Rejected), ns, sub, name)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package promcollector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	cbreaker "go.linecorp.com/garr/circuit-breaker"
+	"go.linecorp.com/garr/circuit-breaker/metrics"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := cbreaker.NewCircuitBreakerBuilder().
+		Name(&cbreaker.Name{Namespace: "ns", Subsystem: "sub", Name: "a"}).
+		AddListener(c).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb.(cbreaker.ManualCircuitBreaker).ForceOpen()
+	cb.CanRequest()
+	cb.CanRequest()
+	cb.OnFailure()
+	_ = c.OnRequestCompleted(cb, cbreaker.OutcomeFailure, true)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				if label.GetName() == metrics.StateLabel || label.GetName() == metrics.ResultLabel {
+					key += "/" + label.GetValue()
+				} else if label.GetValue() == "" {
+					t.Fatal(m)
+				}
+			}
+			if m.GetGauge() != nil {
+				values[key] = m.GetGauge().GetValue()
+			} else {
+				values[key] = m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		metrics.StateMetric + "/closed":              0,
+		metrics.StateMetric + "/forced_open":         1,
+		metrics.RequestsMetric + "/success":          0,
+		metrics.CompletedRequestsMetric + "/failure": 2,
+		metrics.SlowRequestsMetric:                   1,
+		metrics.RejectedRequestsMetric:               2,
+		metrics.TransitionsMetric + "/closed":        1,
+		metrics.TransitionsMetric + "/forced_open":   1,
+		metrics.TransitionsMetric + "/open":          0,
+	}
+	for key, value := range expected {
+		if v, ok := values[key]; !ok || v != value {
+			t.Errorf("%s: %v, expected %v", key, v, value)
+		}
+	}
+	if len(values) != 5+2+2+1+1+5 {
+		t.Fatal(values)
+	}
+}
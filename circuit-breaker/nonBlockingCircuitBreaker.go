@@ -17,9 +17,12 @@ package cbreaker
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync/atomic"
 	"time"
 	"unsafe"
+
+	"go.linecorp.com/garr/retry"
 )
 
 // NonBlockingCircuitBreaker a non-blocking implementation of circuit breaker pattern.
@@ -49,7 +52,7 @@ func NewNonBlockingCircuitBreaker(ticker Ticker, config *CircuitBreakerConfig) (
 	}
 	nbc.s = nbc.newClosedState()
 
-	nbc.logStateTransition(CircuitStateClosed, nil)
+	nbc.logStateTransition(nil, CircuitStateClosed, nil)
 	nbc.notifyStateChanged(CircuitStateClosed)
 
 	return
@@ -72,116 +75,284 @@ func (nb *NonBlockingCircuitBreaker) Name() *Name {
 	return nb.name
 }
 
-// Execute delegated function and reports a success or a failure to the specified CircuitBreaker
-// according to the completed value.
+// Execute delegated function and reports a success or a failure to this circuit breaker
+// according to the completed value, as decided by the configured Classifier.
+// Callers must not report the outcome again with OnSuccess or OnFailure.
 func (nb *NonBlockingCircuitBreaker) Execute(ctx context.Context, delegatedFn Execute) (r interface{}, err error) {
 	if delegatedFn == nil {
 		return
 	}
 
-	if nb.CanRequest() {
-		r, err = delegatedFn(ctx)
-	} else {
+	permittedState, permitted := nb.canRequest()
+	if !permitted {
 		err = ErrFailFast
+		return
+	}
+
+	start := nb.ticker.Tick()
+	r, err = delegatedFn(ctx)
+	elapsed := nb.ticker.Tick() - start
+
+	switch nb.config.GetClassifier()(r, err) {
+	case OutcomeSuccess:
+		nb.onSuccess(nb.isSlowCall(elapsed))
+	case OutcomeFailure:
+		nb.onFailure(nb.isSlowCall(elapsed))
+	case OutcomeIgnore:
+		// an ignored trial request proves nothing, thus gives its permit back for another trial
+		if permittedState.isHalfOpen() {
+			permittedState.releasePermit()
+		}
 	}
 
 	return
 }
 
+// State returns the current circuit state.
+func (nb *NonBlockingCircuitBreaker) State() CircuitState {
+	return nb.state().cs
+}
+
+// ForceOpen changes to FORCED_OPEN, which rejects all requests until the state is changed manually,
+// e.g. during maintenance of the remote service.
+func (nb *NonBlockingCircuitBreaker) ForceOpen() {
+	nb.transition(func(currentState *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+		return newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateForcedOpen, 0, noOpCounter).
+			inheritOpenWindow(currentState)
+	})
+}
+
+// ForceClosed changes to CLOSED, counting events from zero. Unlike Disable, the circuit could be tripped again.
+// Unlike Reset, the number of consecutive OPEN states is kept, thus the open window keeps growing by backoff
+// if the circuit trips again soon. Does nothing if the circuit is already CLOSED, keeping counted events.
+func (nb *NonBlockingCircuitBreaker) ForceClosed() {
+	nb.transition(func(currentState *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+		if currentState.isClosed() {
+			return nil
+		}
+		return nb.newClosedState().inheritOpenWindow(currentState)
+	})
+}
+
+// Disable changes to DISABLED, which allows all requests without counting events until the state is changed manually.
+func (nb *NonBlockingCircuitBreaker) Disable() {
+	nb.transition(func(currentState *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+		return newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateDisabled, 0, noOpCounter).
+			inheritOpenWindow(currentState)
+	})
+}
+
+// Reset returns to the initial state, which is CLOSED without any counted events nor consecutive OPEN states,
+// even if the circuit is already CLOSED.
+func (nb *NonBlockingCircuitBreaker) Reset() {
+	nb.transition(func(*nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+		return nb.newClosedState()
+	})
+}
+
+// transition changes to the state returned by next, which is given the current state.
+// Nothing is changed if next returns nil.
+func (nb *NonBlockingCircuitBreaker) transition(next func(*nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState) {
+	for {
+		currentState := nb.state()
+		newState := next(currentState)
+		if newState == nil {
+			return
+		}
+		if nb.casState(currentState, newState) {
+			nb.logStateTransition(currentState, newState.cs, nil)
+			nb.notifyStateChanged(newState.cs)
+			return
+		}
+	}
+}
+
 // OnSuccess reports a remote invocation success.
 func (nb *NonBlockingCircuitBreaker) OnSuccess() {
+	nb.onSuccess(false)
+}
+
+// OnFailure reports a remote invocation failure.
+func (nb *NonBlockingCircuitBreaker) OnFailure() {
+	nb.onFailure(false)
+}
+
+// onSuccess reports a remote invocation success, which took longer than slowCallDurationThreshold if slow.
+func (nb *NonBlockingCircuitBreaker) onSuccess(slow bool) {
+	nb.notifyRequestCompleted(OutcomeSuccess, slow)
+
 	currentState := nb.state()
 	if currentState.isClosed() {
 		// fires success event
-		if updatedCount := currentState.counter.OnSuccess(); updatedCount != nil {
-			// notifies the count if it has been updated
-			nb.notifyCountUpdated(updatedCount)
+		if updatedCount := countSuccess(currentState.counter, slow); updatedCount != nil {
+			// a successful call could still be slow
+			nb.onCountUpdated(currentState, updatedCount, nb.checkIfExceedingSlowCallThreshold(updatedCount))
 		}
 	} else if currentState.isHalfOpen() {
-		// changes to CLOSED if at least one request succeeds during HALF_OPEN
-		if nb.casState(currentState, nb.newClosedState()) {
-			nb.logStateTransition(CircuitStateClosed, nil)
+		if slow {
+			// a slow trial request does not prove the recovery, thus counts against the trial budget
+			nb.onTrialFailure(currentState, slow)
+			return
+		}
+
+		// changes to CLOSED if enough trial requests succeed during HALF_OPEN
+		if updatedCount := currentState.counter.OnSuccess(); updatedCount.Success() >= nb.config.requiredTrialSuccesses() &&
+			nb.casState(currentState, nb.newClosedState()) {
+			nb.logStateTransition(currentState, CircuitStateClosed, updatedCount)
 			nb.notifyStateChanged(CircuitStateClosed)
 		}
 	}
 }
 
-// OnFailure reports a remote invocation failure.
-func (nb *NonBlockingCircuitBreaker) OnFailure() {
+// onFailure reports a remote invocation failure, which took longer than slowCallDurationThreshold if slow.
+func (nb *NonBlockingCircuitBreaker) onFailure(slow bool) {
+	nb.notifyRequestCompleted(OutcomeFailure, slow)
+
 	currentState := nb.state()
 	if currentState.isClosed() {
 		// fires failure event
-		if updatedCount := currentState.counter.OnFailure(); updatedCount != nil {
-			if nb.checkIfExceedingFailureThreshold(updatedCount) &&
-				nb.casState(currentState, nb.newOpenState()) {
-				nb.logStateTransition(CircuitStateOpen, updatedCount)
-				nb.notifyStateChanged(CircuitStateOpen)
-			} else {
-				nb.notifyCountUpdated(updatedCount)
-			}
+		if updatedCount := countFailure(currentState.counter, slow); updatedCount != nil {
+			nb.onCountUpdated(currentState, updatedCount,
+				nb.checkIfExceedingFailureThreshold(updatedCount) || nb.checkIfExceedingSlowCallThreshold(updatedCount))
 		}
 	} else if currentState.isHalfOpen() {
-		// returns to OPEN if a request fails during HALF_OPEN
-		if nb.casState(currentState, nb.newOpenState()) {
-			nb.logStateTransition(CircuitStateOpen, nil)
-			nb.notifyStateChanged(CircuitStateOpen)
-		}
+		nb.onTrialFailure(currentState, slow)
+	}
+}
+
+// onTrialFailure returns to OPEN if too many trial requests fail or are slow to close the circuit during HALF_OPEN.
+func (nb *NonBlockingCircuitBreaker) onTrialFailure(currentState *nonBlockingCircuitBreakerState, slow bool) {
+	maxFailures := int64(nb.config.GetPermittedTrialRequests()) - nb.config.requiredTrialSuccesses()
+	if updatedCount := countFailure(currentState.counter, slow); updatedCount.Failure() > maxFailures &&
+		nb.casState(currentState, nb.newOpenState(currentState)) {
+		nb.logStateTransition(currentState, CircuitStateOpen, updatedCount)
+		nb.notifyStateChanged(CircuitStateOpen)
+	}
+}
+
+// countSuccess reports a success to the counter, as a slow one if slow and the counter counts slow calls.
+func countSuccess(counter EventCounter, slow bool) *EventCount {
+	if slowCounter, ok := counter.(SlowCallCounter); ok && slow {
+		return slowCounter.OnSlowSuccess()
+	}
+	return counter.OnSuccess()
+}
+
+// countFailure reports a failure to the counter, as a slow one if slow and the counter counts slow calls.
+func countFailure(counter EventCounter, slow bool) *EventCount {
+	if slowCounter, ok := counter.(SlowCallCounter); ok && slow {
+		return slowCounter.OnSlowFailure()
+	}
+	return counter.OnFailure()
+}
+
+// onCountUpdated changes to OPEN if updated count exceeds any threshold, otherwise notifies the count.
+func (nb *NonBlockingCircuitBreaker) onCountUpdated(currentState *nonBlockingCircuitBreakerState, updatedCount *EventCount, exceeding bool) {
+	if exceeding && nb.casState(currentState, nb.newOpenState(currentState)) {
+		nb.logStateTransition(currentState, CircuitStateOpen, updatedCount)
+		nb.notifyStateChanged(CircuitStateOpen)
+	} else {
+		nb.notifyCountUpdated(updatedCount)
 	}
 }
 
 // CanRequest decides whether a request should be sent or failed depending on the current circuit state.
 func (nb *NonBlockingCircuitBreaker) CanRequest() bool {
+	_, permitted := nb.canRequest()
+	return permitted
+}
+
+// canRequest decides whether a request should be sent, returning the state which permitted it,
+// e.g. the HALF_OPEN state holding the trial permit.
+func (nb *NonBlockingCircuitBreaker) canRequest() (*nonBlockingCircuitBreakerState, bool) {
 	currentState := nb.state()
-	if currentState.isClosed() {
-		// all requests are allowed during CLOSED
-		return true
+	if currentState.isClosed() || currentState.cs == CircuitStateDisabled {
+		// all requests are allowed during CLOSED and DISABLED
+		return currentState, true
+	}
+
+	if currentState.isHalfOpen() && currentState.tryAcquirePermit(nb.config.GetPermittedTrialRequests()) {
+		// trial requests are allowed during HALF_OPEN as long as permits remain
+		return currentState, true
 	}
 
 	if currentState.isHalfOpen() || currentState.isOpen() {
-		if currentState.checkTimeout() &&
-			nb.casState(currentState, nb.newHalfOpenState()) {
-			nb.logStateTransition(CircuitStateHalfOpen, nil)
-			nb.notifyStateChanged(CircuitStateHalfOpen)
-			return true
+		// starts a new round of trial requests, taking the first permit
+		if currentState.checkTimeout() {
+			if halfOpenState := nb.newHalfOpenState(currentState); nb.casState(currentState, halfOpenState) {
+				nb.logStateTransition(currentState, CircuitStateHalfOpen, nil)
+				nb.notifyStateChanged(CircuitStateHalfOpen)
+				return halfOpenState, true
+			}
 		}
 
 		// all other requests are refused
 		nb.notifyRequestRejected()
-		return false
+		return currentState, false
 	}
 
-	return true
+	// all requests are refused during FORCED_OPEN
+	nb.notifyRequestRejected()
+	return currentState, false
 }
 
 func (nb *NonBlockingCircuitBreaker) checkIfExceedingFailureThreshold(count *EventCount) bool {
 	total := count.Total()
-	return 0 < total && nb.config.minimumRequestThreshold <= total && nb.config.failureRateThreshold < count.FailureRate()
+	return 0 < total && nb.config.minimumRequests() <= total && nb.config.failureRateThreshold < count.FailureRate()
 }
 
-func (nb *NonBlockingCircuitBreaker) newOpenState() *nonBlockingCircuitBreakerState {
-	return newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateOpen, nb.config.circuitOpenWindow, noOpCounter)
+func (nb *NonBlockingCircuitBreaker) checkIfExceedingSlowCallThreshold(count *EventCount) bool {
+	total := count.Total()
+	return nb.config.slowCallDurationThreshold > 0 &&
+		0 < total && nb.config.minimumRequests() <= total && nb.config.slowCallRateThreshold < count.SlowRate()
+}
+
+func (nb *NonBlockingCircuitBreaker) isSlowCall(elapsedNanos int64) bool {
+	threshold := nb.config.slowCallDurationThreshold
+	return threshold > 0 && threshold.Nanoseconds() <= elapsedNanos
 }
 
-func (nb *NonBlockingCircuitBreaker) newHalfOpenState() *nonBlockingCircuitBreakerState {
-	return newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateHalfOpen, nb.config.trialRequestInterval, noOpCounter)
+// newOpenState returns OPEN state changed from CLOSED or HALF_OPEN, whose open window could grow by backoff.
+// The number of consecutive OPEN states is zero during CLOSED, unless it was closed by ForceClosed.
+func (nb *NonBlockingCircuitBreaker) newOpenState(from *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+	attempts := from.attempts + 1
+	openWindow := nb.config.circuitOpenWindowFor(from.openWindowBackoff, attempts, from.lastOpenWindow)
+	state := newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateOpen, openWindow, noOpCounter).inheritOpenWindow(from)
+	state.attempts = attempts
+	state.lastOpenWindow = openWindow
+	return state
+}
+
+func (nb *NonBlockingCircuitBreaker) newHalfOpenState(from *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+	trials, _ := NewCountBasedWindowCounter(nb.config.GetPermittedTrialRequests())
+	state := newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateHalfOpen, nb.config.trialRequestInterval, trials)
+	state.permits = 1
+	return state.inheritOpenWindow(from)
 }
 
 func (nb *NonBlockingCircuitBreaker) newClosedState() *nonBlockingCircuitBreakerState {
-	slidingWindow, _ := NewSlidingWindowCounter(nb.ticker, nb.config.counterSlidingWindow, nb.config.counterUpdateInterval)
-	return newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateClosed, 0, slidingWindow)
+	var counter EventCounter
+	if nb.config.slidingWindowType == SlidingWindowCountBased {
+		counter, _ = NewCountBasedWindowCounter(nb.config.slidingWindowSize)
+	} else {
+		counter, _ = NewSlidingWindowCounter(nb.ticker, nb.config.counterSlidingWindow, nb.config.counterUpdateInterval)
+	}
+	state := newNonBlockingCircuitBreakerState(nb.ticker, CircuitStateClosed, 0, counter)
+	state.openWindowBackoff = nb.config.freshCircuitOpenWindowBackoff()
+	return state
 }
 
 func (nb *NonBlockingCircuitBreaker) notifyStateChanged(circuitState CircuitState) {
 	for _, listener := range nb.config.listeners {
 		if listener != nil {
 			if err := listener.OnStateChanged(nb, circuitState); err != nil {
-				if logger != nil {
-					logger.Warn("An error occurred when notifying a StateChanged event", err)
+				if lg := nb.log(); lg != nil {
+					lg.Warn("An error occurred when notifying a StateChanged event", "error", err)
 				}
 			}
 			if err := listener.OnEventCountUpdated(nb, EventCountZero); err != nil {
-				if logger != nil {
-					logger.Warn("An error occurred when notifying an EventCountUpdated event", err)
+				if lg := nb.log(); lg != nil {
+					lg.Warn("An error occurred when notifying an EventCountUpdated event", "error", err)
 				}
 			}
 		}
@@ -192,8 +363,8 @@ func (nb *NonBlockingCircuitBreaker) notifyCountUpdated(count *EventCount) {
 	for _, listener := range nb.config.listeners {
 		if listener != nil {
 			if err := listener.OnEventCountUpdated(nb, count); err != nil {
-				if logger != nil {
-					logger.Warn("An error occurred when notifying an EventCountUpdated event", err)
+				if lg := nb.log(); lg != nil {
+					lg.Warn("An error occurred when notifying an EventCountUpdated event", "error", err)
 				}
 			}
 		}
@@ -204,36 +375,60 @@ func (nb *NonBlockingCircuitBreaker) notifyRequestRejected() {
 	for _, listener := range nb.config.listeners {
 		if listener != nil {
 			if err := listener.OnRequestRejected(nb); err != nil {
-				if logger != nil {
-					logger.Warn("An error occurred when notifying a RequestRejected event", err)
+				if lg := nb.log(); lg != nil {
+					lg.Warn("An error occurred when notifying a RequestRejected event", "error", err)
 				}
 			}
 		}
 	}
 }
 
-func (nb *NonBlockingCircuitBreaker) logStateTransition(state CircuitState, count *EventCount) {
-	if logger != nil {
-		var name string
-		if nb.name != nil {
-			name = nb.name.Namespace + "_" + nb.name.Subsystem + "_" + nb.name.Name
+func (nb *NonBlockingCircuitBreaker) notifyRequestCompleted(outcome Outcome, slow bool) {
+	for _, listener := range nb.config.listeners {
+		if outcomeListener, ok := listener.(OutcomeListener); ok {
+			if err := outcomeListener.OnRequestCompleted(nb, outcome, slow); err != nil {
+				if lg := nb.log(); lg != nil {
+					lg.Warn("An error occurred when notifying a RequestCompleted event", "error", err)
+				}
+			}
 		}
+	}
+}
 
-		var _state string
-		if state == CircuitStateOpen {
-			_state = "OPEN"
-		} else if state == CircuitStateHalfOpen {
-			_state = "HALF_OPEN"
-		} else {
-			_state = "CLOSED"
-		}
+// log returns the logger of circuit breaker, or the default logger if it is not set. Returns nil if neither is set.
+func (nb *NonBlockingCircuitBreaker) log() *slog.Logger {
+	if nb.config.logger != nil {
+		return nb.config.logger
+	}
+	return defaultLogger()
+}
 
-		var fl string
-		if count != nil {
-			fl = fmt.Sprintf(" fail:%d total:%d", count.Failure(), count.Total())
-		}
-		logger.Info("name:" + name + " state:" + _state + fl)
+// logStateTransition logs the transition from given state, nil if initial, with the count which caused it if any.
+func (nb *NonBlockingCircuitBreaker) logStateTransition(from *nonBlockingCircuitBreakerState, to CircuitState, count *EventCount) {
+	lg := nb.log()
+	if lg == nil || !lg.Enabled(context.Background(), slog.LevelInfo) {
+		return
 	}
+
+	attrs := make([]slog.Attr, 0, 9)
+	if nb.name != nil {
+		attrs = append(attrs,
+			slog.String("namespace", nb.name.Namespace),
+			slog.String("subsystem", nb.name.Subsystem),
+			slog.String("name", nb.name.Name))
+	}
+	if from != nil {
+		attrs = append(attrs, slog.String("from", from.cs.String()))
+	}
+	attrs = append(attrs, slog.String("to", to.String()))
+	if count != nil {
+		attrs = append(attrs,
+			slog.Int64("failure", count.Failure()),
+			slog.Int64("slow", count.Slow()),
+			slog.Int64("total", count.Total()),
+			slog.Float64("failureRate", count.FailureRate()))
+	}
+	lg.LogAttrs(context.Background(), slog.LevelInfo, "Circuit state changed", attrs...)
 }
 
 // nonBlockingCircuitBreakerState is state inside non blocking circuit breaker.
@@ -243,6 +438,10 @@ type nonBlockingCircuitBreakerState struct {
 	timeout           int64
 	timedOutTimeNanos time.Duration
 	ticker            Ticker
+	permits           int32                 // number of trial requests permitted so far during HALF_OPEN
+	attempts          int                   // number of consecutive OPEN states since the last CLOSED, kept by ForceClosed, used by open window backoff
+	lastOpenWindow    time.Duration         // open window of the last OPEN state since the last CLOSED, kept once open window backoff gives up
+	openWindowBackoff retry.DurationBackoff // fresh for each CLOSED state, kept by ForceClosed, nil if open window is fixed
 }
 
 func newNonBlockingCircuitBreakerState(ticker Ticker, cs CircuitState, timedOutTimeNanos time.Duration, counter EventCounter) *nonBlockingCircuitBreakerState {
@@ -255,10 +454,41 @@ func newNonBlockingCircuitBreakerState(ticker Ticker, cs CircuitState, timedOutT
 	}
 }
 
+// inheritOpenWindow sets the number of consecutive OPEN states, the last open window and the open window backoff of a new state,
+// which is not yet published, from the given state.
+func (ns *nonBlockingCircuitBreakerState) inheritOpenWindow(from *nonBlockingCircuitBreakerState) *nonBlockingCircuitBreakerState {
+	ns.attempts = from.attempts
+	ns.lastOpenWindow = from.lastOpenWindow
+	ns.openWindowBackoff = from.openWindowBackoff
+	return ns
+}
+
 func (ns *nonBlockingCircuitBreakerState) checkTimeout() bool {
 	return ns.timedOutTimeNanos > 0 && ns.timeout <= ns.ticker.Tick()
 }
 
+func (ns *nonBlockingCircuitBreakerState) tryAcquirePermit(permitted int) bool {
+	for {
+		permits := atomic.LoadInt32(&ns.permits)
+		if int(permits) >= permitted {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&ns.permits, permits, permits+1) {
+			return true
+		}
+	}
+}
+
+// releasePermit gives back a trial permit acquired during HALF_OPEN, e.g. by an ignored trial request.
+func (ns *nonBlockingCircuitBreakerState) releasePermit() {
+	for {
+		permits := atomic.LoadInt32(&ns.permits)
+		if permits <= 0 || atomic.CompareAndSwapInt32(&ns.permits, permits, permits-1) {
+			return
+		}
+	}
+}
+
 func (ns *nonBlockingCircuitBreakerState) isOpen() bool {
 	return ns.cs == CircuitStateOpen
 }
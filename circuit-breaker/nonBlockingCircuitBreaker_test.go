@@ -16,11 +16,15 @@ package cbreaker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"go.linecorp.com/garr/retry"
 )
 
 func TestNoopCounter(t *testing.T) {
@@ -114,7 +118,7 @@ func TestNonBlockingCircuitBreaker_MoreSuccess(t *testing.T) {
 	nbc, _ := NewNonBlockingCircuitBreaker(SystemTicker, validConfig)
 
 	// set start state is opened
-	nbc.s = nbc.newOpenState()
+	nbc.s = nbc.newOpenState(nbc.state())
 
 	// fake notify
 	nbc.notifyCountUpdated(&EventCount{})
@@ -136,8 +140,8 @@ func TestNonBlockingCircuitBreaker_MoreSuccess(t *testing.T) {
 					}
 					return
 				}); err != nil {
+					// failure is recorded by Execute
 					count++
-					nbc.OnFailure()
 					time.Sleep(time.Millisecond >> 4)
 				} else if nbc.CanRequest() {
 					count++
@@ -182,8 +186,8 @@ func TestNonBlockingCircuitBreaker_Failure(t *testing.T) {
 					}
 					return
 				}); err == nil {
+					// success is recorded by Execute
 					count++
-					nbc.OnSuccess()
 					time.Sleep(time.Millisecond >> 8)
 				} else if nbc.CanRequest() {
 					count++
@@ -199,3 +203,481 @@ func TestNonBlockingCircuitBreaker_Failure(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestNonBlockingCircuitBreaker_Classifier(t *testing.T) {
+	errBadRequest := fmt.Errorf("bad request")
+
+	cb, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 4).
+		SetFailureRateThreshold(0.5).
+		SetClassifier(func(result interface{}, err error) Outcome {
+			if errors.Is(err, context.Canceled) || errors.Is(err, errBadRequest) {
+				return OutcomeIgnore
+			}
+			if result == "retry later" {
+				return OutcomeFailure
+			}
+			return DefaultClassifier(result, err)
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbc := cb.(*NonBlockingCircuitBreaker)
+
+	execute := func(result interface{}, err error) {
+		if _, e := cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+			return result, err
+		}); e != err {
+			t.Fatal(e)
+		}
+	}
+
+	// ignored calls are not recorded
+	execute(nil, context.Canceled)
+	execute(nil, errBadRequest)
+	if c := nbc.state().counter.Count(); c.Total() != 0 {
+		t.Fatal(c)
+	}
+
+	execute("ok", nil)
+	execute("retry later", nil)
+	execute(nil, fmt.Errorf("remote error"))
+	if c := nbc.state().counter.Count(); c.Success() != 1 || c.Failure() != 2 {
+		t.Fatal(c)
+	}
+
+	execute(nil, fmt.Errorf("remote error"))
+	if _, err := cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+		t.Fatal("must not be called")
+		return nil, nil
+	}); !errors.Is(err, ErrFailFast) {
+		t.Fatal(err)
+	}
+}
+
+type fakeTicker struct {
+	tick int64
+}
+
+func (f *fakeTicker) Tick() int64 {
+	return atomic.LoadInt64(&f.tick)
+}
+
+func (f *fakeTicker) advance(d time.Duration) {
+	atomic.AddInt64(&f.tick, int64(d))
+}
+
+func TestNonBlockingCircuitBreaker_IgnoredTrialRequest(t *testing.T) {
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		SetPermittedTrialRequests(1).
+		SetClassifier(func(result interface{}, err error) Outcome {
+			if errors.Is(err, context.Canceled) {
+				return OutcomeIgnore
+			}
+			return DefaultClassifier(result, err)
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbc := cb.(*NonBlockingCircuitBreaker)
+
+	cb.OnFailure()
+	ticker.advance(defaultCircuitOpenWindow)
+
+	// the ignored trial request gives its permit back
+	if _, err := cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+		return nil, context.Canceled
+	}); !errors.Is(err, context.Canceled) || !nbc.state().isHalfOpen() {
+		t.Fatal(err, nbc.state().cs)
+	}
+	if !cb.CanRequest() || cb.CanRequest() {
+		t.Fatal()
+	}
+
+	cb.OnSuccess()
+	if !nbc.state().isClosed() {
+		t.Fatal(nbc.state().cs)
+	}
+}
+
+func TestNonBlockingCircuitBreaker_TrialRequests(t *testing.T) {
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 2).
+		SetFailureRateThreshold(0.5).
+		SetPermittedTrialRequests(4).
+		SetTrialSuccessRateThreshold(0.5).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbc := cb.(*NonBlockingCircuitBreaker)
+
+	open := func() {
+		t.Helper()
+		cb.OnFailure()
+		cb.OnFailure()
+		if !nbc.state().isOpen() {
+			t.Fatal(nbc.state().cs)
+		}
+
+		ticker.advance(defaultCircuitOpenWindow)
+		for i := 0; i < 4; i++ {
+			if !cb.CanRequest() {
+				t.Fatal(i)
+			}
+		}
+		if cb.CanRequest() || !nbc.state().isHalfOpen() {
+			t.Fatal()
+		}
+	}
+
+	// 2 of 4 trial requests must succeed to close
+	open()
+	cb.OnSuccess()
+	cb.OnFailure()
+	if !nbc.state().isHalfOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+	cb.OnSuccess()
+	if !nbc.state().isClosed() {
+		t.Fatal(nbc.state().cs)
+	}
+
+	// 3 failures out of 4 trial requests can no longer reach the ratio
+	open()
+	cb.OnFailure()
+	cb.OnSuccess()
+	cb.OnFailure()
+	if !nbc.state().isHalfOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+	cb.OnFailure()
+	if !nbc.state().isOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+
+	// a new round of trial requests starts after trial request interval
+	ticker.advance(defaultCircuitOpenWindow)
+	cb.CanRequest()
+	ticker.advance(defaultTrialRequestInterval)
+	for i := 0; i < 4; i++ {
+		if !cb.CanRequest() {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestNonBlockingCircuitBreaker_SlowTrialRequests(t *testing.T) {
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 2).
+		SetFailureRateThreshold(0.5).
+		SetSlowCallDurationThreshold(time.Second).
+		SetPermittedTrialRequests(4).
+		SetTrialSuccessRateThreshold(0.5).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbc := cb.(*NonBlockingCircuitBreaker)
+
+	execute := func(d time.Duration) {
+		t.Helper()
+		if _, err := cb.Execute(context.Background(), func(context.Context) (interface{}, error) {
+			ticker.advance(d)
+			return nil, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cb.OnFailure()
+	cb.OnFailure()
+	ticker.advance(defaultCircuitOpenWindow)
+
+	// a slow trial request does not reopen the circuit as long as the ratio could be reached
+	execute(time.Second)
+	execute(time.Second)
+	if !nbc.state().isHalfOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+	if count := nbc.state().counter.Count(); count.Failure() != 2 || count.Slow() != 2 {
+		t.Fatal(count)
+	}
+
+	execute(0)
+	if !nbc.state().isHalfOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+
+	// 3 slow or failed trial requests out of 4 can no longer reach the ratio
+	execute(time.Second)
+	if !nbc.state().isOpen() {
+		t.Fatal(nbc.state().cs)
+	}
+}
+
+type stateRecorder struct {
+	dummyCircuitBreakerListener
+	mutex  sync.Mutex
+	states []CircuitState
+}
+
+func (s *stateRecorder) OnStateChanged(cb CircuitBreaker, state CircuitState) (err error) {
+	s.mutex.Lock()
+	s.states = append(s.states, state)
+	s.mutex.Unlock()
+	return
+}
+
+func TestNonBlockingCircuitBreaker_ManualControl(t *testing.T) {
+	SetDefaultLogger(&fakeLogger{})
+	defer SetDefaultLogger(nil)
+
+	recorder := &stateRecorder{}
+	built, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		AddListener(recorder).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := built.(ManualCircuitBreaker)
+
+	cb.ForceOpen()
+	if cb.State() != CircuitStateForcedOpen || cb.CanRequest() || loggedInfo != "Circuit state changed from:CLOSED to:FORCED_OPEN" {
+		t.Fatal(cb.State(), loggedInfo)
+	}
+
+	// events are not counted and the state never times out
+	cb.OnSuccess()
+	if cb.State() != CircuitStateForcedOpen {
+		t.Fatal(cb.State())
+	}
+
+	cb.Disable()
+	cb.OnFailure()
+	if cb.State() != CircuitStateDisabled || !cb.CanRequest() || loggedInfo != "Circuit state changed from:FORCED_OPEN to:DISABLED" {
+		t.Fatal(cb.State(), loggedInfo)
+	}
+
+	// closed circuit could be tripped again
+	cb.ForceClosed()
+	cb.OnFailure()
+	if cb.State() != CircuitStateOpen || cb.CanRequest() {
+		t.Fatal(cb.State())
+	}
+
+	cb.Reset()
+	if cb.State() != CircuitStateClosed || !cb.CanRequest() {
+		t.Fatal(cb.State())
+	}
+
+	expected := []CircuitState{
+		CircuitStateClosed, CircuitStateForcedOpen, CircuitStateDisabled,
+		CircuitStateClosed, CircuitStateOpen, CircuitStateClosed,
+	}
+	if fmt.Sprint(recorder.states) != fmt.Sprint(expected) {
+		t.Fatal(recorder.states)
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	if CircuitStateHalfOpen.String() != "HALF_OPEN" || CircuitState(100).String() != "UNKNOWN" {
+		t.FailNow()
+	}
+}
+
+func TestNonBlockingCircuitBreaker_OpenWindowBackoff(t *testing.T) {
+	backoff, _ := retry.NewExponentialBackoff(1000, 3000, 2)
+
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		SetCircuitOpenWindowBackoff(backoff).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// waits for the open window, then fails the trial request
+	expectOpenWindow := func(window time.Duration) {
+		t.Helper()
+		ticker.advance(window - time.Millisecond)
+		if cb.CanRequest() {
+			t.Fatal(window)
+		}
+		ticker.advance(time.Millisecond)
+		if !cb.CanRequest() {
+			t.Fatal(window)
+		}
+	}
+
+	cb.OnFailure()
+	expectOpenWindow(time.Second)
+	cb.OnFailure()
+	expectOpenWindow(2 * time.Second)
+	cb.OnFailure()
+	expectOpenWindow(3 * time.Second)
+	cb.OnFailure()
+	expectOpenWindow(3 * time.Second)
+
+	// resets on CLOSED
+	cb.OnSuccess()
+	if state := cb.(ManualCircuitBreaker).State(); state != CircuitStateClosed {
+		t.Fatal(state)
+	}
+	cb.OnFailure()
+	expectOpenWindow(time.Second)
+
+	// ForceClosed keeps growing the open window, while Reset does not
+	cb.OnFailure()
+	cb.(ManualCircuitBreaker).ForceClosed()
+	cb.OnFailure()
+	expectOpenWindow(3 * time.Second)
+
+	cb.(ManualCircuitBreaker).Reset()
+	cb.OnFailure()
+	expectOpenWindow(time.Second)
+}
+
+func TestNonBlockingCircuitBreaker_AttemptLimitedOpenWindowBackoff(t *testing.T) {
+	exponential, _ := retry.NewExponentialBackoff(1000, 10000, 2)
+	backoff, _ := retry.NewAttemptLimitingBackoff(exponential, 3)
+
+	ticker := &fakeTicker{}
+	cb, err := NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		SetCircuitOpenWindow(500 * time.Millisecond).
+		SetCircuitOpenWindowBackoff(backoff).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := cb.(*NonBlockingCircuitBreaker)
+
+	// fails the trial request after each open window
+	expectOpenWindow := func(window time.Duration) {
+		t.Helper()
+		if actual := nb.state().timedOutTimeNanos; actual != window {
+			t.Fatal(actual, window)
+		}
+		ticker.advance(window)
+		if !cb.CanRequest() {
+			t.Fatal(window)
+		}
+		cb.OnFailure()
+	}
+
+	cb.OnFailure()
+	expectOpenWindow(time.Second)
+	expectOpenWindow(2 * time.Second)
+	// the backoff gives up, keeping the last open window instead of the fixed one
+	expectOpenWindow(2 * time.Second)
+	expectOpenWindow(2 * time.Second)
+
+	// falls back to the fixed one if the backoff gives up at once
+	noRetry, _ := retry.NewAttemptLimitingBackoff(exponential, 1)
+	cb, _ = NewCircuitBreakerBuilder().
+		SetTicker(ticker).
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		SetCircuitOpenWindow(500 * time.Millisecond).
+		SetCircuitOpenWindowBackoff(noRetry).
+		Build()
+	nb = cb.(*NonBlockingCircuitBreaker)
+	cb.OnFailure()
+	expectOpenWindow(500 * time.Millisecond)
+	expectOpenWindow(500 * time.Millisecond)
+}
+
+// growingBackoff is a StatefulBackoff whose delay grows by a second at every call, whatever the attempt is.
+type growingBackoff struct {
+	calls int64
+}
+
+func (g *growingBackoff) NextDelayMillis(int) int64 {
+	g.calls++
+	return g.calls * 1000
+}
+
+func (g *growingBackoff) Fresh() retry.Backoff {
+	return &growingBackoff{}
+}
+
+func TestNonBlockingCircuitBreaker_FreshOpenWindowBackoff(t *testing.T) {
+	shared := &growingBackoff{}
+	ticker := &fakeTicker{}
+	config, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 1).
+		SetCircuitOpenWindowBackoff(shared).
+		BuildConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := NewNonBlockingCircuitBreaker(ticker, config)
+	b, _ := NewNonBlockingCircuitBreaker(ticker, config)
+
+	// each circuit breaker opens for a second first, by its own backoff
+	a.OnFailure()
+	b.OnFailure()
+	for _, cb := range []*NonBlockingCircuitBreaker{a, b} {
+		if window := cb.state().timedOutTimeNanos; window != time.Second {
+			t.Fatal(window)
+		}
+	}
+
+	// grows by failed trials
+	ticker.advance(time.Second)
+	a.CanRequest()
+	a.OnFailure()
+	if window := a.state().timedOutTimeNanos; window != 2*time.Second {
+		t.Fatal(window)
+	}
+
+	// renewed on CLOSED
+	ticker.advance(2 * time.Second)
+	a.CanRequest()
+	a.OnSuccess()
+	a.OnFailure()
+	if window := a.state().timedOutTimeNanos; window != time.Second || a.State() != CircuitStateOpen {
+		t.Fatal(window)
+	}
+
+	if shared.calls != 0 {
+		t.Fatal(shared.calls)
+	}
+}
+
+func TestNonBlockingCircuitBreaker_ForceClosedAndReset(t *testing.T) {
+	built, err := NewCircuitBreakerBuilder().
+		SetSlidingWindowType(SlidingWindowCountBased, 2).
+		SetFailureRateThreshold(0.5).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := built.(*NonBlockingCircuitBreaker)
+
+	// ForceClosed keeps the counted events of CLOSED circuit
+	cb.OnFailure()
+	cb.ForceClosed()
+	if count := cb.state().counter.Count(); count.Failure() != 1 {
+		t.Fatal(count)
+	}
+
+	// Reset does not
+	cb.Reset()
+	if count := cb.state().counter.Count(); count.Total() != 0 || cb.State() != CircuitStateClosed {
+		t.Fatal(count)
+	}
+}
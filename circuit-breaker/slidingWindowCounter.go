@@ -24,11 +24,20 @@ import (
 	queue "go.linecorp.com/garr/queue"
 )
 
+// event is the kind of event counted by bucket.
+type event byte
+
+const (
+	eventSuccess event = iota
+	eventFailure
+)
+
 // bucket hold the count of events within {@code updateInterval}.
 type bucket struct {
 	timestamp int64
 	s         ga.LongAdder // number of success
 	f         ga.LongAdder // number of failure
+	sl        ga.LongAdder // number of slow calls
 }
 
 // create new bucket
@@ -37,15 +46,20 @@ func newBucket(timestamp int64) *bucket {
 		timestamp: timestamp,
 		s:         ga.NewLongAdder(ga.JDKAdderType),
 		f:         ga.NewLongAdder(ga.JDKAdderType),
+		sl:        ga.NewLongAdder(ga.JDKAdderType),
 	}
 }
 
-func (b *bucket) add(succ bool) {
-	if succ {
+func (b *bucket) add(ev event, slow bool) {
+	switch ev {
+	case eventSuccess:
 		b.s.Add(1)
-	} else {
+	case eventFailure:
 		b.f.Add(1)
 	}
+	if slow {
+		b.sl.Add(1)
+	}
 }
 
 // returns number of success operation
@@ -58,6 +72,11 @@ func (b *bucket) failure() int64 {
 	return b.f.Sum()
 }
 
+// returns number of slow operation
+func (b *bucket) slow() int64 {
+	return b.sl.Sum()
+}
+
 // SlidingWindowCounter accumulates the count of events within a time window.
 type SlidingWindowCounter struct {
 	ticker              Ticker
@@ -65,7 +84,7 @@ type SlidingWindowCounter struct {
 	slidingWindowNanos  int64
 	updateIntervalNanos int64
 	snapshot            atomic.Value
-	reservoir           queue.Queue
+	reservoir           *queue.JDKLinkedQueueOf[*bucket]
 }
 
 // NewSlidingWindowCounter creates new SlidingWindowCounter.
@@ -80,7 +99,7 @@ func NewSlidingWindowCounter(ticker Ticker, slidingWindowNanos, updateIntervalNa
 		slidingWindowNanos:  int64(slidingWindowNanos),
 		updateIntervalNanos: int64(updateIntervalNanos),
 		cur:                 newBucket(ticker.Tick()),
-		reservoir:           queue.DefaultQueue(),
+		reservoir:           queue.NewJDKLinkedQueueOf[*bucket](),
 	}
 	s.snapshot.Store(EventCountZero)
 	return
@@ -107,36 +126,50 @@ func (s *SlidingWindowCounter) Count() *EventCount {
 // Return success/failure count of current sliding window on every update interval.
 // Return nil if events are still being accumulated in current bucket.
 func (s *SlidingWindowCounter) OnSuccess() *EventCount {
-	return s.onEvent(true)
+	return s.onEvent(eventSuccess, false)
 }
 
 // OnFailure adds failure event to the current bucket.
 // Return success/failure count of current sliding window on every update interval.
 // Return nil if events are still being accumulated in current bucket
 func (s *SlidingWindowCounter) OnFailure() *EventCount {
-	return s.onEvent(false)
+	return s.onEvent(eventFailure, false)
+}
+
+// OnSlowSuccess adds success event of a slow call to the current bucket.
+// Return success/failure count of current sliding window on every update interval.
+// Return nil if events are still being accumulated in current bucket
+func (s *SlidingWindowCounter) OnSlowSuccess() *EventCount {
+	return s.onEvent(eventSuccess, true)
+}
+
+// OnSlowFailure adds failure event of a slow call to the current bucket.
+// Return success/failure count of current sliding window on every update interval.
+// Return nil if events are still being accumulated in current bucket
+func (s *SlidingWindowCounter) OnSlowFailure() *EventCount {
+	return s.onEvent(eventFailure, true)
 }
 
-func (s *SlidingWindowCounter) onEvent(succ bool) (e *EventCount) {
+func (s *SlidingWindowCounter) onEvent(ev event, slow bool) (e *EventCount) {
 	tickerNanos, currentBucket := s.ticker.Tick(), s.current()
 
 	if tickerNanos < currentBucket.timestamp {
 		// if current timestamp is older than bucket's timestamp (maybe race or GC pause?),
 		// then creates an instant bucket and puts it to the reservoir not to lose event.
 		bucket := newBucket(tickerNanos)
-		bucket.add(succ)
+		bucket.add(ev, slow)
 		s.reservoir.Offer(bucket)
 		return
 	}
 
 	if tickerNanos < currentBucket.timestamp+s.updateIntervalNanos {
 		// Events are still being accumulated in current bucket.
-		currentBucket.add(succ)
+		currentBucket.add(ev, slow)
 		return
 	}
 
 	nextBucket := newBucket(tickerNanos)
-	nextBucket.add(succ)
+	nextBucket.add(ev, slow)
 
 	// replaces the bucket
 	if s.casCurrent(currentBucket, nextBucket) {
@@ -155,23 +188,21 @@ func (s *SlidingWindowCounter) onEvent(succ bool) (e *EventCount) {
 }
 
 func (s *SlidingWindowCounter) trimAndSum(t int64) *EventCount {
-	oldLimit, iterator := t-s.slidingWindowNanos, s.reservoir.Iterator()
-
-	var nxt interface{}
-	var bck *bucket
-	var success, failure int64
-
-	for iterator.HasNext() {
-		if nxt = iterator.Next(); nxt != nil {
-			if bck = nxt.(*bucket); bck.timestamp < oldLimit {
-				// removes old bucket
-				iterator.Remove()
-			} else {
-				success += bck.success()
-				failure += bck.failure()
-			}
+	oldLimit := t - s.slidingWindowNanos
+
+	// removes old buckets
+	s.reservoir.RemoveIf(func(b *bucket) bool {
+		return b.timestamp < oldLimit
+	})
+
+	var success, failure, slow int64
+	for iterator := s.reservoir.Iterator(); iterator.HasNext(); {
+		if bck := iterator.Next(); bck != nil && bck.timestamp >= oldLimit {
+			success += bck.success()
+			failure += bck.failure()
+			slow += bck.slow()
 		}
 	}
 
-	return NewEventCount(success, failure)
+	return NewEventCountWithSlow(success, failure, slow)
 }
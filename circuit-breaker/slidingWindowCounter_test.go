@@ -74,3 +74,19 @@ func Test_StressSlidingWindow(t *testing.T) {
 	cancel()
 	wg.Wait()
 }
+
+func Test_SlidingWindowSlow(t *testing.T) {
+	counter, _ := NewSlidingWindowCounter(
+		SystemTicker,
+		time.Second,
+		time.Millisecond,
+	)
+
+	counter.OnSlowSuccess()
+	counter.OnFailure()
+	time.Sleep(2 * time.Millisecond)
+
+	if e := counter.OnSuccess(); e == nil || e.Slow() != 1 || e.Total() != 2 {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+// binaryHeap is a min-heap of T ordered by less. It is not safe for concurrent use.
+type binaryHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *binaryHeap[T]) len() int {
+	return len(h.items)
+}
+
+func (h *binaryHeap[T]) push(v T) {
+	h.items = append(h.items, v)
+	h.siftUp(len(h.items) - 1)
+}
+
+func (h *binaryHeap[T]) peek() (v T, ok bool) {
+	if len(h.items) > 0 {
+		v, ok = h.items[0], true
+	}
+	return
+}
+
+func (h *binaryHeap[T]) pop() (v T, ok bool) {
+	n := len(h.items) - 1
+	if n < 0 {
+		return
+	}
+
+	var zero T
+	v, ok = h.items[0], true
+	h.items[0], h.items[n] = h.items[n], zero
+	h.items = h.items[:n]
+	h.siftDown(0)
+	return
+}
+
+func (h *binaryHeap[T]) siftUp(i int) {
+	for i > 0 {
+		parent := (i - 1) >> 1
+		if !h.less(h.items[i], h.items[parent]) {
+			return
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+func (h *binaryHeap[T]) siftDown(i int) {
+	n := len(h.items)
+	for {
+		smallest := i
+		if left := i<<1 + 1; left < n && h.less(h.items[left], h.items[smallest]) {
+			smallest = left
+		}
+		if right := i<<1 + 2; right < n && h.less(h.items[right], h.items[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type delayedEntry[T any] struct {
+	v        T
+	deadline int64
+	seq      uint64
+}
+
+// DelayQueueOf is an unbounded, thread-safe queue of delayed elements, similar to OpenJDK DelayQueue.
+// An element can only be taken when its delay has expired. The head of the queue is the element
+// whose delay expired furthest in the past, elements with the same deadline are retrieved in insertion order.
+//
+// Time is measured and waited on by the Ticker given at construction, thus the queue could be tested
+// deterministically with a Ticker implementing Clock.
+type DelayQueueOf[T any] struct {
+	heap      binaryHeap[delayedEntry[T]]
+	seq       uint64
+	clock     Clock
+	mutex     sync.Mutex
+	available chan struct{}
+}
+
+// NewDelayQueueOf creates new DelayQueueOf driven by the given ticker. If ticker is nil, SystemTicker is used.
+// If ticker does not implement Clock, Take assumes that it advances in real time while waiting.
+func NewDelayQueueOf[T any](ticker Ticker) *DelayQueueOf[T] {
+	if ticker == nil {
+		ticker = SystemTicker
+	}
+
+	return &DelayQueueOf[T]{
+		heap: binaryHeap[delayedEntry[T]]{
+			less: func(a, b delayedEntry[T]) bool {
+				return a.deadline < b.deadline || a.deadline == b.deadline && a.seq < b.seq
+			},
+		},
+		clock:     clockOf(ticker),
+		available: make(chan struct{}, 1),
+	}
+}
+
+// OfferAfter inserts the specified element into this queue, which becomes available after the given delay.
+// As the queue is unbounded, this method will never return false.
+func (q *DelayQueueOf[T]) OfferAfter(v T, delay time.Duration) bool {
+	q.mutex.Lock()
+	q.seq++
+	q.heap.push(delayedEntry[T]{v: v, deadline: deadlineOf(q.clock.Tick(), delay), seq: q.seq})
+	head, _ := q.heap.peek()
+	isHead := head.seq == q.seq
+	q.mutex.Unlock()
+
+	// wake up a waiting routine if the new element becomes head
+	if isHead {
+		signal(q.available)
+	}
+	return true
+}
+
+// deadlineOf returns the tick at which the given delay expires, capped at math.MaxInt64 instead of overflowing,
+// so that a huge delay never expires.
+func deadlineOf(now int64, delay time.Duration) int64 {
+	if delay > 0 && int64(delay) > math.MaxInt64-now {
+		return math.MaxInt64
+	}
+	return now + delay.Nanoseconds()
+}
+
+// Offer inserts the specified element into this queue, which is available immediately.
+func (q *DelayQueueOf[T]) Offer(v T) bool {
+	return q.OfferAfter(v, 0)
+}
+
+// Poll retrieves and removes the head of this queue if its delay has expired, without waiting.
+// Returns false if this queue is empty or the delay of its head has not yet expired.
+func (q *DelayQueueOf[T]) Poll() (v T, ok bool) {
+	q.mutex.Lock()
+	if head, has := q.heap.peek(); has && head.deadline <= q.clock.Tick() {
+		q.heap.pop()
+		v, ok = head.v, true
+	}
+	q.mutex.Unlock()
+	return
+}
+
+// Take retrieves and removes the head of this queue, waiting if necessary until an element
+// with an expired delay is available. Returns ctx.Err() if ctx is done before that.
+func (q *DelayQueueOf[T]) Take(ctx context.Context) (v T, err error) {
+	for {
+		q.mutex.Lock()
+		head, has := q.heap.peek()
+		delay := time.Duration(head.deadline - q.clock.Tick())
+		if has && delay <= 0 {
+			q.heap.pop()
+			remaining := q.heap.len()
+			q.mutex.Unlock()
+
+			// let other waiting routines recompute their delay for the new head
+			if remaining > 0 {
+				signal(q.available)
+			}
+			return head.v, nil
+		}
+		q.mutex.Unlock()
+
+		var expired <-chan struct{}
+		var stop func()
+		if has {
+			expired, stop = q.clock.After(delay)
+		}
+
+		select {
+		case <-q.available:
+		case <-expired:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if stop != nil {
+			stop()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Unlike Poll, the head is returned
+// even if its delay has not yet expired. Returns false if this queue is empty.
+func (q *DelayQueueOf[T]) Peek() (v T, ok bool) {
+	q.mutex.Lock()
+	head, ok := q.heap.peek()
+	q.mutex.Unlock()
+	return head.v, ok
+}
+
+// Size returns the number of elements in this queue, including unexpired ones.
+func (q *DelayQueueOf[T]) Size() (size int32) {
+	q.mutex.Lock()
+	size = int32(q.heap.len())
+	q.mutex.Unlock()
+	return
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *DelayQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, both expired and unexpired ones,
+// taken under the lock, in no particular order. Remove of the iterator does nothing.
+func (q *DelayQueueOf[T]) Iterator() IteratorOf[T] {
+	q.mutex.Lock()
+	values := make([]T, len(q.heap.items))
+	for i := range q.heap.items {
+		values[i] = q.heap.items[i].v
+	}
+	q.mutex.Unlock()
+	return newSnapshotIter(values)
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type manualWaiter struct {
+	deadline int64
+	ch       chan struct{}
+}
+
+// manualTicker is a Clock which only advances when told to.
+type manualTicker struct {
+	tick    int64
+	mutex   sync.Mutex
+	waiters map[*manualWaiter]struct{}
+}
+
+func (m *manualTicker) Tick() int64 {
+	return atomic.LoadInt64(&m.tick)
+}
+
+func (m *manualTicker) After(d time.Duration) (<-chan struct{}, func()) {
+	w := &manualWaiter{deadline: m.Tick() + int64(d), ch: make(chan struct{})}
+
+	m.mutex.Lock()
+	if m.waiters == nil {
+		m.waiters = make(map[*manualWaiter]struct{})
+	}
+	m.waiters[w] = struct{}{}
+	m.mutex.Unlock()
+
+	m.advance(0)
+	return w.ch, func() {
+		m.mutex.Lock()
+		delete(m.waiters, w)
+		m.mutex.Unlock()
+	}
+}
+
+func (m *manualTicker) advance(d time.Duration) {
+	tick := atomic.AddInt64(&m.tick, int64(d))
+
+	m.mutex.Lock()
+	for w := range m.waiters {
+		if w.deadline <= tick {
+			close(w.ch)
+			delete(m.waiters, w)
+		}
+	}
+	m.mutex.Unlock()
+}
+
+// deadlines returns the deadlines of routines waiting on the ticker.
+func (m *manualTicker) deadlines() (deadlines []time.Duration) {
+	m.mutex.Lock()
+	for w := range m.waiters {
+		deadlines = append(deadlines, time.Duration(w.deadline))
+	}
+	m.mutex.Unlock()
+	return
+}
+
+func TestDelayQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewDelayQueueOf[int](nil))
+}
+
+func TestDelayQueueOf_Expiry(t *testing.T) {
+	ticker := &manualTicker{}
+	q := NewDelayQueueOf[int](ticker)
+
+	q.OfferAfter(3, 3*time.Second)
+	q.OfferAfter(1, time.Second)
+	q.OfferAfter(2, 2*time.Second)
+	q.OfferAfter(4, 2*time.Second)
+
+	if _, ok := q.Poll(); ok {
+		t.Fatal()
+	}
+
+	// peek returns head even if not expired
+	if v, ok := q.Peek(); !ok || v != 1 || q.Size() != 4 {
+		t.Fatal(v, ok)
+	}
+
+	ticker.advance(time.Second)
+	if v, ok := q.Poll(); !ok || v != 1 {
+		t.Fatal(v, ok)
+	}
+	if _, ok := q.Poll(); ok {
+		t.Fatal()
+	}
+
+	// same deadline is retrieved in insertion order
+	ticker.advance(5 * time.Second)
+	for _, expected := range []int{2, 4, 3} {
+		if v, ok := q.Poll(); !ok || v != expected {
+			t.Fatal(v, ok)
+		}
+	}
+
+	if !q.IsEmpty() {
+		t.Fatal()
+	}
+}
+
+func TestDelayQueueOf_Iterator(t *testing.T) {
+	ticker := &manualTicker{}
+	q := NewDelayQueueOf[int](ticker)
+	testSnapshotIteratorOf(t, q, false)
+
+	// unexpired elements are included
+	q = NewDelayQueueOf[int](ticker)
+	q.OfferAfter(1, time.Hour)
+	if iter := q.Iterator(); !iter.HasNext() || iter.Next() != 1 || iter.HasNext() {
+		t.Fatal()
+	}
+}
+
+func TestDelayQueueOf_HugeDelay(t *testing.T) {
+	ticker := &manualTicker{}
+	ticker.advance(time.Hour)
+	q := NewDelayQueueOf[int](ticker)
+
+	// deadlines would overflow, which must not make elements available at once
+	q.OfferAfter(1, math.MaxInt64)
+	q.OfferAfter(2, math.MaxInt64-time.Minute)
+	q.OfferAfter(3, time.Second)
+
+	ticker.advance(time.Second)
+	if v, ok := q.Poll(); !ok || v != 3 {
+		t.Fatal(v, ok)
+	}
+	ticker.advance(24 * time.Hour)
+	if v, ok := q.Poll(); ok || q.Size() != 2 {
+		t.Fatal(v, ok)
+	}
+
+	// waits until ctx is done
+	q = NewDelayQueueOf[int](nil)
+	q.OfferAfter(1, math.MaxInt64)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Take(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+}
+
+func TestDelayQueueOf_Take(t *testing.T) {
+	q := NewDelayQueueOf[int](nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	q.OfferAfter(1, time.Hour)
+	if _, err := q.Take(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+
+	// blocked Take is woken up by an earlier element
+	done := make(chan int)
+	go func() {
+		v, err := q.Take(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	start := time.Now()
+	q.OfferAfter(2, 30*time.Millisecond)
+	if v := <-done; v != 2 {
+		t.Fatal(v)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatal(elapsed)
+	}
+
+	if v, ok := q.Peek(); !ok || v != 1 {
+		t.Fatal(v, ok)
+	}
+}
+
+func TestDelayQueueOf_TakeManualTicker(t *testing.T) {
+	ticker := &manualTicker{}
+	q := NewDelayQueueOf[int](ticker)
+	q.OfferAfter(1, time.Hour)
+
+	done := make(chan int)
+	go func() {
+		v, err := q.Take(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	waitFor := func(deadline time.Duration) {
+		for d := ticker.deadlines(); len(d) != 1 || d[0] != deadline; d = ticker.deadlines() {
+			runtime.Gosched()
+		}
+	}
+
+	// Take waits on the ticker rather than on real time
+	waitFor(time.Hour)
+	ticker.advance(time.Minute)
+	select {
+	case v := <-done:
+		t.Fatal(v)
+	default:
+	}
+
+	// an earlier element makes Take wait for the new deadline instead
+	q.OfferAfter(2, time.Second)
+	waitFor(time.Minute + time.Second)
+	ticker.advance(time.Second)
+	if v := <-done; v != 2 {
+		t.Fatal(v)
+	}
+
+	ticker.advance(time.Hour)
+	if v, err := q.Take(context.Background()); err != nil || v != 1 || len(ticker.deadlines()) != 0 {
+		t.Fatal(v, err)
+	}
+}
@@ -0,0 +1,716 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// hops is the threshold of deleted nodes to squeeze out in unlink.
+const hops = 2
+
+// JDKLinkedDequeOf represents jdk-based concurrent non blocking linked-list deque,
+// ported from OpenJDK ConcurrentLinkedDeque.
+//
+// A node contains an item which is nil once the element is removed. Deleted nodes are
+// gradually unlinked, and nodes which are no longer reachable point to themselves or to
+// terminators, so that concurrent traversals could detect them and restart.
+//
+// Iterators are weakly consistent: they never return an element twice and may or may not
+// reflect concurrent updates since their creation.
+type JDKLinkedDequeOf[T any] struct {
+	// The padding members 1 to 3 below are here to ensure each item is on a separate cache line.
+	// This prevents false sharing and hence improves performance.
+	_ internal.CacheLinePad
+	h unsafe.Pointer // head
+	_ internal.CacheLinePad
+	t unsafe.Pointer // tail
+	_ internal.CacheLinePad
+
+	prevTerminator *dequeNode[T]
+	nextTerminator *dequeNode[T]
+}
+
+// NewJDKLinkedDequeOf creates new JDKLinkedDequeOf.
+func NewJDKLinkedDequeOf[T any]() *JDKLinkedDequeOf[T] {
+	d := &JDKLinkedDequeOf[T]{
+		t:              unsafe.Pointer(&dequeNode[T]{}),
+		prevTerminator: &dequeNode[T]{},
+		nextTerminator: &dequeNode[T]{},
+	}
+	d.h = d.t
+	d.prevTerminator.setNextNode(d.prevTerminator)
+	d.nextTerminator.setPrevNode(d.nextTerminator)
+	return d
+}
+
+func (d *JDKLinkedDequeOf[T]) head() *dequeNode[T] {
+	return (*dequeNode[T])(atomic.LoadPointer(&d.h))
+}
+
+func (d *JDKLinkedDequeOf[T]) tail() *dequeNode[T] {
+	return (*dequeNode[T])(atomic.LoadPointer(&d.t))
+}
+
+func (d *JDKLinkedDequeOf[T]) casHead(old, new *dequeNode[T]) bool {
+	return atomic.CompareAndSwapPointer(&d.h, unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+func (d *JDKLinkedDequeOf[T]) casTail(old, new *dequeNode[T]) bool {
+	return atomic.CompareAndSwapPointer(&d.t, unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+// OfferFirst inserts the specified element at the front of this deque.
+// As the deque is unbounded, this method will never return false.
+func (d *JDKLinkedDequeOf[T]) OfferFirst(v T) bool {
+	newNode := newDequeNode(v)
+
+restartFromHead:
+	for {
+		h := d.head()
+		p := h
+		for {
+			if q := p.prevNode(); q != nil {
+				if p, q = q, q.prevNode(); q != nil {
+					// Check for head updates every other hop.
+					// If p == q, we are sure to follow head instead.
+					if newH := d.head(); newH != h {
+						h, p = newH, newH
+					} else {
+						p = q
+					}
+					continue
+				}
+			}
+
+			if p.nextNode() == p { // prevTerminator
+				continue restartFromHead
+			}
+
+			// p is first node
+			newNode.setNextNode(p)
+			if p.casPrevNode(nil, newNode) {
+				// Successful CAS is the linearization point
+				// for v to become an element of this deque,
+				// and for newNode to become "live".
+				if p != h { // hop two nodes at a time
+					d.casHead(h, newNode) // Failure is OK.
+				}
+				return true
+			}
+			// Lost CAS race to another routine; re-read prev
+		}
+	}
+}
+
+// OfferLast inserts the specified element at the end of this deque.
+// As the deque is unbounded, this method will never return false.
+func (d *JDKLinkedDequeOf[T]) OfferLast(v T) bool {
+	newNode := newDequeNode(v)
+
+restartFromTail:
+	for {
+		t := d.tail()
+		p := t
+		for {
+			if q := p.nextNode(); q != nil {
+				if p, q = q, q.nextNode(); q != nil {
+					// Check for tail updates every other hop.
+					// If p == q, we are sure to follow tail instead.
+					if newT := d.tail(); newT != t {
+						t, p = newT, newT
+					} else {
+						p = q
+					}
+					continue
+				}
+			}
+
+			if p.prevNode() == p { // nextTerminator
+				continue restartFromTail
+			}
+
+			// p is last node
+			newNode.setPrevNode(p)
+			if p.casNextNode(nil, newNode) {
+				// Successful CAS is the linearization point
+				// for v to become an element of this deque,
+				// and for newNode to become "live".
+				if p != t { // hop two nodes at a time
+					d.casTail(t, newNode) // Failure is OK.
+				}
+				return true
+			}
+			// Lost CAS race to another routine; re-read next
+		}
+	}
+}
+
+// Offer inserts the specified element at the end of this deque. Same as OfferLast.
+func (d *JDKLinkedDequeOf[T]) Offer(v T) bool {
+	return d.OfferLast(v)
+}
+
+// PollFirst retrieves and removes the first element of this deque. Returns false if this deque is empty.
+func (d *JDKLinkedDequeOf[T]) PollFirst() (v T, ok bool) {
+	for p := d.first(); p != nil; p = d.succ(p) {
+		if item := p.item(); item != nil && p.casItemNil(item) {
+			d.unlink(p)
+			return p.value(), true
+		}
+	}
+	return
+}
+
+// PollLast retrieves and removes the last element of this deque. Returns false if this deque is empty.
+func (d *JDKLinkedDequeOf[T]) PollLast() (v T, ok bool) {
+	for p := d.last(); p != nil; p = d.pred(p) {
+		if item := p.item(); item != nil && p.casItemNil(item) {
+			d.unlink(p)
+			return p.value(), true
+		}
+	}
+	return
+}
+
+// Poll retrieves and removes the first element of this deque. Same as PollFirst.
+func (d *JDKLinkedDequeOf[T]) Poll() (T, bool) {
+	return d.PollFirst()
+}
+
+// PeekFirst retrieves, but does not remove, the first element of this deque. Returns false if this deque is empty.
+func (d *JDKLinkedDequeOf[T]) PeekFirst() (v T, ok bool) {
+	for p := d.first(); p != nil; p = d.succ(p) {
+		if p.hasItem() {
+			return p.value(), true
+		}
+	}
+	return
+}
+
+// PeekLast retrieves, but does not remove, the last element of this deque. Returns false if this deque is empty.
+func (d *JDKLinkedDequeOf[T]) PeekLast() (v T, ok bool) {
+	for p := d.last(); p != nil; p = d.pred(p) {
+		if p.hasItem() {
+			return p.value(), true
+		}
+	}
+	return
+}
+
+// Peek retrieves, but does not remove, the first element of this deque. Same as PeekFirst.
+func (d *JDKLinkedDequeOf[T]) Peek() (T, bool) {
+	return d.PeekFirst()
+}
+
+// IsEmpty returns if this deque contains no elements.
+func (d *JDKLinkedDequeOf[T]) IsEmpty() bool {
+	_, ok := d.PeekFirst()
+	return !ok
+}
+
+// Size returns the number of elements in this deque. If this deque
+// contains more than math.MaxInt32 elements, returns math.MaxInt32.
+// Like JDKLinkedQueueOf, this method is NOT a constant-time operation and
+// the returned result may be inaccurate if elements are added or removed concurrently.
+func (d *JDKLinkedDequeOf[T]) Size() (count int32) {
+	for p := d.first(); p != nil; p = d.succ(p) {
+		if p.hasItem() {
+			if count++; count == math.MaxInt32 {
+				break
+			}
+		}
+	}
+	return
+}
+
+// Iterator returns iterator over the elements in this deque in proper sequence, from first to last.
+func (d *JDKLinkedDequeOf[T]) Iterator() IteratorOf[T] {
+	return newJdkLinkedDequeIter(d, d.first, d.succ)
+}
+
+// DescendingIterator returns iterator over the elements in this deque in reverse sequential order,
+// from last to first.
+func (d *JDKLinkedDequeOf[T]) DescendingIterator() IteratorOf[T] {
+	return newJdkLinkedDequeIter(d, d.last, d.pred)
+}
+
+// first returns the first node, the unique node p for which:
+// p.prev == nil && p.next != p.
+// The returned node may or may not be logically deleted.
+// Guarantees that head is set to the returned node.
+func (d *JDKLinkedDequeOf[T]) first() *dequeNode[T] {
+restartFromHead:
+	for {
+		h := d.head()
+		p := h
+		for {
+			if q := p.prevNode(); q != nil {
+				if p, q = q, q.prevNode(); q != nil {
+					if newH := d.head(); newH != h {
+						h, p = newH, newH
+					} else {
+						p = q
+					}
+					continue
+				}
+			}
+
+			// It is possible that p is prevTerminator,
+			// but if so, the CAS is guaranteed to fail.
+			if p == h || d.casHead(h, p) {
+				return p
+			}
+			continue restartFromHead
+		}
+	}
+}
+
+// last returns the last node, the unique node p for which:
+// p.next == nil && p.prev != p.
+// The returned node may or may not be logically deleted.
+// Guarantees that tail is set to the returned node.
+func (d *JDKLinkedDequeOf[T]) last() *dequeNode[T] {
+restartFromTail:
+	for {
+		t := d.tail()
+		p := t
+		for {
+			if q := p.nextNode(); q != nil {
+				if p, q = q, q.nextNode(); q != nil {
+					if newT := d.tail(); newT != t {
+						t, p = newT, newT
+					} else {
+						p = q
+					}
+					continue
+				}
+			}
+
+			// It is possible that p is nextTerminator,
+			// but if so, the CAS is guaranteed to fail.
+			if p == t || d.casTail(t, p) {
+				return p
+			}
+			continue restartFromTail
+		}
+	}
+}
+
+// succ returns the successor of p, or the first node if p.next has been
+// linked to self, which will only be true if traversing with a
+// stale pointer that is now off the list.
+func (d *JDKLinkedDequeOf[T]) succ(p *dequeNode[T]) *dequeNode[T] {
+	if q := p.nextNode(); q != p {
+		return q
+	}
+	return d.first()
+}
+
+// pred returns the predecessor of p, or the last node if p.prev has been
+// linked to self, which will only be true if traversing with a
+// stale pointer that is now off the list.
+func (d *JDKLinkedDequeOf[T]) pred(p *dequeNode[T]) *dequeNode[T] {
+	if q := p.prevNode(); q != p {
+		return q
+	}
+	return d.last()
+}
+
+// unlink non-nil node x, which has been logically deleted.
+func (d *JDKLinkedDequeOf[T]) unlink(x *dequeNode[T]) {
+	prev, next := x.prevNode(), x.nextNode()
+	if prev == nil {
+		d.unlinkFirst(x, next)
+		return
+	}
+	if next == nil {
+		d.unlinkLast(x, prev)
+		return
+	}
+
+	// Unlink interior node.
+	//
+	// This is the common case, since a series of polls at the
+	// same end will be "interior" removes, except perhaps for
+	// the first one, since end nodes cannot be unlinked.
+	//
+	// At any time, all active nodes are mutually reachable by
+	// following a sequence of either next or prev pointers.
+	//
+	// Our strategy is to find the unique active predecessor
+	// and successor of x. Try to fix up their links so that
+	// they point to each other, leaving x unreachable from
+	// active nodes. If successful, and if x has no live
+	// predecessor/successor, we additionally try to gc-unlink,
+	// leaving active nodes unreachable from x, by rechecking
+	// that the status of predecessor and successor are
+	// unchanged and ensuring that x is not reachable from
+	// tail/head, before setting x's prev/next links to their
+	// logical approximate replacements, self/terminator.
+	var activePred, activeSucc *dequeNode[T]
+	var isFirst, isLast bool
+	numHops := 1
+
+	// Find active predecessor
+	for p := prev; ; numHops++ {
+		if p.hasItem() {
+			activePred = p
+			break
+		}
+
+		q := p.prevNode()
+		if q == nil {
+			if p.nextNode() == p {
+				return
+			}
+			activePred, isFirst = p, true
+			break
+		}
+		if p == q {
+			return
+		}
+		p = q
+	}
+
+	// Find active successor
+	for p := next; ; numHops++ {
+		if p.hasItem() {
+			activeSucc = p
+			break
+		}
+
+		q := p.nextNode()
+		if q == nil {
+			if p.prevNode() == p {
+				return
+			}
+			activeSucc, isLast = p, true
+			break
+		}
+		if p == q {
+			return
+		}
+		p = q
+	}
+
+	// always squeeze out interior deleted nodes
+	if numHops < hops && (isFirst || isLast) {
+		return
+	}
+
+	// Squeeze out deleted nodes between activePred and
+	// activeSucc, including x.
+	d.skipDeletedSuccessors(activePred)
+	d.skipDeletedPredecessors(activeSucc)
+
+	// Try to gc-unlink, if possible
+	if (isFirst || isLast) &&
+		// Recheck expected state of predecessor and successor
+		activePred.nextNode() == activeSucc &&
+		activeSucc.prevNode() == activePred &&
+		(isFirst && activePred.prevNode() == nil || !isFirst && activePred.hasItem()) &&
+		(isLast && activeSucc.nextNode() == nil || !isLast && activeSucc.hasItem()) {
+
+		d.updateHead() // Ensure x is not reachable from head
+		d.updateTail() // Ensure x is not reachable from tail
+
+		// Finally, actually gc-unlink
+		if isFirst {
+			x.setPrevNode(d.prevTerminator)
+		} else {
+			x.setPrevNode(x)
+		}
+		if isLast {
+			x.setNextNode(d.nextTerminator)
+		} else {
+			x.setNextNode(x)
+		}
+	}
+}
+
+// unlinkFirst unlinks non-nil first node.
+func (d *JDKLinkedDequeOf[T]) unlinkFirst(first, next *dequeNode[T]) {
+	var o *dequeNode[T]
+	for p := next; ; {
+		q := p.nextNode()
+		if p.hasItem() || q == nil {
+			if o != nil && p.prevNode() != p && first.casNextNode(next, p) {
+				d.skipDeletedPredecessors(p)
+				if first.prevNode() == nil &&
+					(p.nextNode() == nil || p.hasItem()) &&
+					p.prevNode() == first {
+
+					d.updateHead() // Ensure o is not reachable from head
+					d.updateTail() // Ensure o is not reachable from tail
+
+					// Finally, actually gc-unlink
+					o.setNextNode(o)
+					o.setPrevNode(d.prevTerminator)
+				}
+			}
+			return
+		}
+		if p == q {
+			return
+		}
+		o, p = p, q
+	}
+}
+
+// unlinkLast unlinks non-nil last node.
+func (d *JDKLinkedDequeOf[T]) unlinkLast(last, prev *dequeNode[T]) {
+	var o *dequeNode[T]
+	for p := prev; ; {
+		q := p.prevNode()
+		if p.hasItem() || q == nil {
+			if o != nil && p.nextNode() != p && last.casPrevNode(prev, p) {
+				d.skipDeletedSuccessors(p)
+				if last.nextNode() == nil &&
+					(p.prevNode() == nil || p.hasItem()) &&
+					p.nextNode() == last {
+
+					d.updateHead() // Ensure o is not reachable from head
+					d.updateTail() // Ensure o is not reachable from tail
+
+					// Finally, actually gc-unlink
+					o.setPrevNode(o)
+					o.setNextNode(d.nextTerminator)
+				}
+			}
+			return
+		}
+		if p == q {
+			return
+		}
+		o, p = p, q
+	}
+}
+
+// updateHead guarantees that any node which was unlinked before a call to
+// this method will be unreachable from head after it returns.
+// Does not guarantee to eliminate slack, only that head will
+// point to a node that was active while this method was running.
+func (d *JDKLinkedDequeOf[T]) updateHead() {
+	// Either head already points to an active node, or we keep
+	// trying to cas it to the first node until it does.
+restartFromHead:
+	for {
+		h := d.head()
+		if h.hasItem() {
+			return
+		}
+
+		p := h.prevNode()
+		if p == nil {
+			return
+		}
+
+		for {
+			q := p.prevNode()
+			if q != nil {
+				p = q
+				q = p.prevNode()
+			}
+
+			if q == nil {
+				// It is possible that p is prevTerminator,
+				// but if so, the CAS is guaranteed to fail.
+				if d.casHead(h, p) {
+					return
+				}
+				continue restartFromHead
+			}
+
+			if h != d.head() {
+				continue restartFromHead
+			}
+			p = q
+		}
+	}
+}
+
+// updateTail guarantees that any node which was unlinked before a call to
+// this method will be unreachable from tail after it returns.
+// Does not guarantee to eliminate slack, only that tail will
+// point to a node that was active while this method was running.
+func (d *JDKLinkedDequeOf[T]) updateTail() {
+	// Either tail already points to an active node, or we keep
+	// trying to cas it to the last node until it does.
+restartFromTail:
+	for {
+		t := d.tail()
+		if t.hasItem() {
+			return
+		}
+
+		p := t.nextNode()
+		if p == nil {
+			return
+		}
+
+		for {
+			q := p.nextNode()
+			if q != nil {
+				p = q
+				q = p.nextNode()
+			}
+
+			if q == nil {
+				// It is possible that p is nextTerminator,
+				// but if so, the CAS is guaranteed to fail.
+				if d.casTail(t, p) {
+					return
+				}
+				continue restartFromTail
+			}
+
+			if t != d.tail() {
+				continue restartFromTail
+			}
+			p = q
+		}
+	}
+}
+
+func (d *JDKLinkedDequeOf[T]) skipDeletedPredecessors(x *dequeNode[T]) {
+whileActive:
+	for {
+		prev := x.prevNode()
+		p := prev
+
+	findActive:
+		for !p.hasItem() {
+			q := p.prevNode()
+			if q == nil {
+				if p.nextNode() == p {
+					goto next
+				}
+				break findActive
+			}
+			if p == q {
+				goto next
+			}
+			p = q
+		}
+
+		// found active CAS target
+		if prev == p || x.casPrevNode(prev, p) {
+			return
+		}
+
+	next:
+		if !x.hasItem() && x.nextNode() != nil {
+			break whileActive
+		}
+	}
+}
+
+func (d *JDKLinkedDequeOf[T]) skipDeletedSuccessors(x *dequeNode[T]) {
+whileActive:
+	for {
+		next := x.nextNode()
+		p := next
+
+	findActive:
+		for !p.hasItem() {
+			q := p.nextNode()
+			if q == nil {
+				if p.prevNode() == p {
+					goto next
+				}
+				break findActive
+			}
+			if p == q {
+				goto next
+			}
+			p = q
+		}
+
+		// found active CAS target
+		if next == p || x.casNextNode(next, p) {
+			return
+		}
+
+	next:
+		if !x.hasItem() && x.prevNode() != nil {
+			break whileActive
+		}
+	}
+}
+
+type jdkLinkedDequeIter[T any] struct {
+	d        *JDKLinkedDequeOf[T]
+	nextFn   func(*dequeNode[T]) *dequeNode[T]
+	nextNode *dequeNode[T]
+	nextVal  T
+	lastRet  *dequeNode[T]
+}
+
+func newJdkLinkedDequeIter[T any](d *JDKLinkedDequeOf[T], start func() *dequeNode[T], nextFn func(*dequeNode[T]) *dequeNode[T]) *jdkLinkedDequeIter[T] {
+	iter := &jdkLinkedDequeIter[T]{
+		d:      d,
+		nextFn: nextFn,
+	}
+	iter.advance(start())
+	return iter
+}
+
+// advance to the first live node starting from p.
+func (i *jdkLinkedDequeIter[T]) advance(p *dequeNode[T]) {
+	for ; p != nil; p = i.nextFn(p) {
+		if p.hasItem() {
+			i.nextNode, i.nextVal = p, p.value()
+			return
+		}
+	}
+
+	var zero T
+	i.nextNode, i.nextVal = nil, zero
+}
+
+// HasNext returns true if has next.
+func (i *jdkLinkedDequeIter[T]) HasNext() bool {
+	return i.nextNode != nil
+}
+
+// Next return next elements. There is no guarantee that hasNext and next are atomically due to data racy.
+func (i *jdkLinkedDequeIter[T]) Next() (v T) {
+	p := i.nextNode
+	if p == nil {
+		return
+	}
+
+	v, i.lastRet = i.nextVal, p
+	i.advance(i.nextFn(p))
+	return
+}
+
+// Remove from the underlying collection the last element returned
+// by this iterator.
+func (i *jdkLinkedDequeIter[T]) Remove() {
+	l := i.lastRet
+	if l == nil {
+		return
+	}
+
+	l.setItemNil()
+	i.d.unlink(l)
+	i.lastRet = nil
+}
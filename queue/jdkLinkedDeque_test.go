@@ -0,0 +1,205 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestJDKLinkedDequeOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewJDKLinkedDequeOf[int]())
+}
+
+func TestJDKLinkedDequeOf_BothEnds(t *testing.T) {
+	var d DequeOf[int] = NewJDKLinkedDequeOf[int]()
+
+	if _, ok := d.PeekLast(); ok {
+		t.Fatal()
+	}
+	if _, ok := d.PollLast(); ok {
+		t.Fatal()
+	}
+
+	// 4 3 2 1 0 5 6 7 8 9
+	for i := 0; i < 5; i++ {
+		d.OfferFirst(i)
+		d.OfferLast(i + 5)
+	}
+
+	if v, ok := d.PeekFirst(); !ok || v != 4 {
+		t.Fatal(v, ok)
+	}
+	if v, ok := d.PeekLast(); !ok || v != 9 {
+		t.Fatal(v, ok)
+	}
+	if d.Size() != 10 {
+		t.Fatal(d.Size())
+	}
+
+	expected := []int{9, 8, 7, 6, 5, 0, 1, 2, 3, 4}
+	i := 0
+	for iter := d.DescendingIterator(); iter.HasNext(); i++ {
+		if v := iter.Next(); v != expected[i] {
+			t.Fatal(i, v)
+		}
+	}
+	if i != len(expected) {
+		t.Fatal(i)
+	}
+
+	for i := 0; i < 5; i++ {
+		if v, ok := d.PollLast(); !ok || v != 9-i {
+			t.Fatal(v, ok)
+		}
+		if v, ok := d.PollFirst(); !ok || v != 4-i {
+			t.Fatal(v, ok)
+		}
+	}
+
+	if !d.IsEmpty() || d.Size() != 0 {
+		t.Fatal()
+	}
+}
+
+func TestJDKLinkedDequeOf_Iterator(t *testing.T) {
+	d := NewJDKLinkedDequeOf[int]()
+	for i := 0; i < 100; i++ {
+		d.OfferLast(i)
+	}
+
+	expected := 0
+	for iter := d.Iterator(); iter.HasNext(); expected++ {
+		if v := iter.Next(); v != expected {
+			t.Fatal(v, expected)
+		} else if v%2 == 0 {
+			iter.Remove()
+		}
+	}
+
+	if expected != 100 || d.Size() != 50 {
+		t.Fatal(expected, d.Size())
+	}
+
+	expected = 99
+	for iter := d.DescendingIterator(); iter.HasNext(); expected -= 2 {
+		if v := iter.Next(); v != expected {
+			t.Fatal(v, expected)
+		} else if v > 50 {
+			iter.Remove()
+		}
+	}
+
+	if v, ok := d.PeekLast(); !ok || v != 49 || d.Size() != 25 {
+		t.Fatal(v, ok, d.Size())
+	}
+}
+
+func TestJDKLinkedDequeOf_Race(t *testing.T) {
+	d := NewJDKLinkedDequeOf[int]()
+
+	const numberRoutine = 4
+	total := numberRoutine * numberEle * 2
+
+	seen := make([]int32, total)
+	var polled int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < numberRoutine; i++ {
+		wg.Add(2)
+		go func(producer int) {
+			defer wg.Done()
+			for j := 0; j < numberEle; j++ {
+				d.OfferFirst(producer*numberEle*2 + j*2)
+				d.OfferLast(producer*numberEle*2 + j*2 + 1)
+			}
+		}(i)
+
+		go func(consumer int) {
+			defer wg.Done()
+			for atomic.LoadInt32(&polled) < int32(total) {
+				var v int
+				var ok bool
+				if consumer%2 == 0 {
+					v, ok = d.PollFirst()
+				} else {
+					v, ok = d.PollLast()
+				}
+
+				if ok {
+					atomic.AddInt32(&seen[v], 1)
+					atomic.AddInt32(&polled, 1)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range seen {
+		if seen[i] != 1 {
+			t.Fatal(i, seen[i])
+		}
+	}
+
+	if !d.IsEmpty() {
+		t.Fatal(d.Size())
+	}
+}
+
+func TestJDKLinkedDequeOf_WorkStealing(t *testing.T) {
+	d := NewJDKLinkedDequeOf[int]()
+
+	var stolen, owned int32
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	// thieves steal from the front
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					if _, ok := d.PollFirst(); ok {
+						atomic.AddInt32(&stolen, 1)
+					}
+				}
+			}
+		}()
+	}
+
+	// owner pushes and pops at the end
+	for i := 0; i < numberEle; i++ {
+		d.OfferLast(i)
+		d.OfferLast(i)
+		if _, ok := d.PollLast(); ok {
+			owned++
+		}
+	}
+	for _, ok := d.PollLast(); ok; _, ok = d.PollLast() {
+		owned++
+	}
+
+	close(done)
+	wg.Wait()
+
+	if stolen+owned != 2*numberEle {
+		t.Fatal(stolen, owned)
+	}
+}
@@ -15,15 +15,17 @@
 package queue
 
 import (
+	"iter"
 	"math"
 	"sync/atomic"
 	"unsafe"
 
+	ga "go.linecorp.com/garr/adder"
 	"go.linecorp.com/garr/internal"
 )
 
-// JDKLinkedQueue represents jdk-based concurrent non blocking linked-list queue.
-type JDKLinkedQueue struct {
+// JDKLinkedQueueOf represents jdk-based concurrent non blocking linked-list queue of T.
+type JDKLinkedQueueOf[T any] struct {
 	// The padding members 1 to 3 below are here to ensure each item is on a separate cache line.
 	// This prevents false sharing and hence improves performance.
 	_ internal.CacheLinePad
@@ -31,73 +33,84 @@ type JDKLinkedQueue struct {
 	_ internal.CacheLinePad
 	t unsafe.Pointer // tail
 	_ internal.CacheLinePad
+
+	size ga.LongAdder // number of elements, nil if size tracking is disabled
 }
 
-// NewJDKLinkedQueue creates new JDKLinkedQueue.
-func NewJDKLinkedQueue() *JDKLinkedQueue {
-	q := &JDKLinkedQueue{
-		t: unsafe.Pointer(&linkedListNode{}),
+// NewJDKLinkedQueueOf creates new JDKLinkedQueueOf.
+func NewJDKLinkedQueueOf[T any]() *JDKLinkedQueueOf[T] {
+	q := &JDKLinkedQueueOf[T]{
+		t: unsafe.Pointer(&linkedListNode[T]{}),
 	}
 	q.h = q.t
 	return q
 }
 
-func (queue *JDKLinkedQueue) head() unsafe.Pointer {
+// NewSizeTrackingJDKLinkedQueueOf creates new JDKLinkedQueueOf which tracks its number of elements
+// with a LongAdder, thus ApproximateSize is a constant-time operation. Tracking costs an extra
+// LongAdder update per insertion and removal.
+func NewSizeTrackingJDKLinkedQueueOf[T any]() *JDKLinkedQueueOf[T] {
+	q := NewJDKLinkedQueueOf[T]()
+	q.size = ga.NewLongAdder(ga.JDKAdderType)
+	return q
+}
+
+func (queue *JDKLinkedQueueOf[T]) head() unsafe.Pointer {
 	return atomic.LoadPointer(&queue.h)
 }
 
-func (queue *JDKLinkedQueue) tail() unsafe.Pointer {
+func (queue *JDKLinkedQueueOf[T]) tail() unsafe.Pointer {
 	return atomic.LoadPointer(&queue.t)
 }
 
 // Offer inserts the specified element at the tail of this queue.
-func (queue *JDKLinkedQueue) Offer(v interface{}) {
-	if v != nil {
-		newNode := unsafe.Pointer(newLinkedListNode(v))
-
-		var oldT unsafe.Pointer
-
-		t := queue.tail()
-		p := t
-		for {
-			_p := (*linkedListNode)(p)
-			if q := _p.next(); q == nil {
-				// p is last node
-				if _p.casNext(nil, newNode) {
-					// Successful CAS is the linearization point
-					// for e to become an element of this queue,
-					// and for newNode to become "live".
-					if p != t { // hop two nodes at a time
-						queue.casTail(t, newNode) // Failure is OK.
-					}
-					return
-				}
-				// Lost CAS race to another thread; re-read next
-			} else if p == q {
-				// We have fallen off list.  If tail is unchanged, it
-				// will also be off-list, in which case we need to
-				// jump to head, from which all live nodes are always
-				// reachable.  Else the new tail is a better bet.
-				if oldT, t = t, queue.tail(); oldT != t { // t != (t = tail)?
-					p = t
-				} else {
-					p = queue.head()
-				}
-			} else if p != t { // Check for tail updates after two hops.
-				if oldT, t = t, queue.tail(); oldT != t {
-					p = t
-				} else {
-					p = q
+// As the queue is unbounded, this method will never return false.
+func (queue *JDKLinkedQueueOf[T]) Offer(v T) bool {
+	newNode := unsafe.Pointer(newLinkedListNode(v))
+
+	var oldT unsafe.Pointer
+
+	t := queue.tail()
+	p := t
+	for {
+		_p := (*linkedListNode[T])(p)
+		if q := _p.next(); q == nil {
+			// p is last node
+			if _p.casNext(nil, newNode) {
+				// Successful CAS is the linearization point
+				// for e to become an element of this queue,
+				// and for newNode to become "live".
+				if p != t { // hop two nodes at a time
+					queue.casTail(t, newNode) // Failure is OK.
 				}
+				queue.addSize(1)
+				return true
+			}
+			// Lost CAS race to another thread; re-read next
+		} else if p == q {
+			// We have fallen off list.  If tail is unchanged, it
+			// will also be off-list, in which case we need to
+			// jump to head, from which all live nodes are always
+			// reachable.  Else the new tail is a better bet.
+			if oldT, t = t, queue.tail(); oldT != t { // t != (t = tail)?
+				p = t
+			} else {
+				p = queue.head()
+			}
+		} else if p != t { // Check for tail updates after two hops.
+			if oldT, t = t, queue.tail(); oldT != t {
+				p = t
 			} else {
 				p = q
 			}
+		} else {
+			p = q
 		}
 	}
 }
 
-// Poll head element.
-func (queue *JDKLinkedQueue) Poll() interface{} {
+// Poll retrieves and removes head element. Returns false if this queue is empty.
+func (queue *JDKLinkedQueueOf[T]) Poll() (v T, ok bool) {
 loop:
 	for {
 		var q unsafe.Pointer
@@ -105,9 +118,9 @@ loop:
 		h := queue.head()
 		p := h
 		for ; ; p = q {
-			_p := (*linkedListNode)(p)
+			_p := (*linkedListNode[T])(p)
 			if item := _p.item(); item != nil && _p.casItemNil(item) {
-				v := _p.value()
+				v = _p.value()
 				// Successful CAS is the linearization point
 				// for item to be removed from this queue.
 				if p != h { // hop two nodes at a time
@@ -118,12 +131,13 @@ loop:
 						queue.updateHead(h, p)
 					}
 				}
-				return v
+				queue.addSize(-1)
+				return v, true
 			}
 
 			if q = _p.next(); q == nil {
 				queue.updateHead(h, p)
-				return nil
+				return
 			}
 
 			if p == q {
@@ -133,8 +147,8 @@ loop:
 	}
 }
 
-// Peek return head element
-func (queue *JDKLinkedQueue) Peek() interface{} {
+// Peek retrieves, but does not remove head element. Returns false if this queue is empty.
+func (queue *JDKLinkedQueueOf[T]) Peek() (v T, ok bool) {
 loop:
 	for {
 		var q unsafe.Pointer
@@ -142,16 +156,16 @@ loop:
 		h := queue.head()
 		p := h
 		for ; ; p = q {
-			_p := (*linkedListNode)(p)
+			_p := (*linkedListNode[T])(p)
 			if item := _p.item(); item != nil {
-				v := _p.value()
+				v = _p.value()
 				queue.updateHead(h, p)
-				return v
+				return v, true
 			}
 
 			if q = _p.next(); q == nil {
 				queue.updateHead(h, p)
-				return nil
+				return
 			}
 
 			if p == q {
@@ -167,7 +181,7 @@ loop:
 // first(), but that would cost an extra volatile read of item,
 // and the need to add a retry loop to deal with the possibility
 // of losing a race to a concurrent poll().
-func (queue *JDKLinkedQueue) first() unsafe.Pointer {
+func (queue *JDKLinkedQueueOf[T]) first() unsafe.Pointer {
 loop:
 	for {
 		var q unsafe.Pointer
@@ -175,7 +189,7 @@ loop:
 		h := queue.head()
 		p := h
 		for ; ; p = q {
-			_p := (*linkedListNode)(p)
+			_p := (*linkedListNode[T])(p)
 
 			hasItem := _p.item() != nil
 			if hasItem {
@@ -195,8 +209,179 @@ loop:
 	}
 }
 
+// AddAll appends all of the given elements to the tail of this queue, in order.
+// The elements are linked into a chain first, then the chain is appended atomically,
+// thus elements of concurrent Offer never interleave with them.
+// Returns false if values is empty.
+func (queue *JDKLinkedQueueOf[T]) AddAll(values ...T) bool {
+	if len(values) == 0 {
+		return false
+	}
+
+	// copy values into a private chain of nodes
+	beginningOfTheEnd := newLinkedListNode(values[0])
+	last := beginningOfTheEnd
+	for _, v := range values[1:] {
+		newNode := newLinkedListNode(v)
+		last._n = unsafe.Pointer(newNode)
+		last = newNode
+	}
+	begin, end := unsafe.Pointer(beginningOfTheEnd), unsafe.Pointer(last)
+
+	// atomically append the chain at the tail of this queue
+	var oldT unsafe.Pointer
+
+	t := queue.tail()
+	p := t
+	for {
+		_p := (*linkedListNode[T])(p)
+		if q := _p.next(); q == nil {
+			// p is last node
+			if _p.casNext(nil, begin) {
+				// Successful CAS is the linearization point
+				// for all elements to be added to this queue.
+				if !queue.casTail(t, end) {
+					// Try a little harder to update tail,
+					// since we may be adding many elements.
+					t = queue.tail()
+					if last.next() == nil {
+						queue.casTail(t, end)
+					}
+				}
+				queue.addSize(int64(len(values)))
+				return true
+			}
+			// Lost CAS race to another thread; re-read next
+		} else if p == q {
+			// We have fallen off list.  If tail is unchanged, it
+			// will also be off-list, in which case we need to
+			// jump to head, from which all live nodes are always
+			// reachable.  Else the new tail is a better bet.
+			if oldT, t = t, queue.tail(); oldT != t {
+				p = t
+			} else {
+				p = queue.head()
+			}
+		} else if p != t { // Check for tail updates after two hops.
+			if oldT, t = t, queue.tail(); oldT != t {
+				p = t
+			} else {
+				p = q
+			}
+		} else {
+			p = q
+		}
+	}
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed.
+func (queue *JDKLinkedQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	var next, pred unsafe.Pointer
+	for p := queue.first(); p != nil; pred, p = p, next {
+		removed := false
+
+		_p := (*linkedListNode[T])(p)
+		if item := _p.item(); item != nil {
+			if !match(_p.value()) {
+				next = queue.succ(p)
+				continue
+			}
+			removed = _p.casItemNil(item)
+		}
+
+		next = queue.succ(p)
+		if pred != nil && next != nil { // unlink
+			(*linkedListNode[T])(pred).casNext(p, next)
+		}
+
+		if removed {
+			queue.addSize(-1)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveIf removes all elements of this queue which satisfy filter, in a single traversal.
+// Returns true if any element was removed.
+//
+// Elements added or removed concurrently may or may not be tested by filter,
+// and filter may be called more than once for the same element.
+func (queue *JDKLinkedQueueOf[T]) RemoveIf(filter func(T) bool) (removed bool) {
+	const maxHops = 8
+
+loop:
+	for {
+		hops := maxHops
+
+		// c will be CASed to collapse intervening dead nodes between
+		// pred (or head if nil) and p.
+		var pred, q unsafe.Pointer
+		p := queue.head()
+		for c := p; p != nil; p = q {
+			_p := (*linkedListNode[T])(p)
+			q = _p.next()
+
+			item := _p.item()
+			pAlive := item != nil
+			if pAlive && filter(_p.value()) {
+				if _p.casItemNil(item) {
+					removed = true
+					queue.addSize(-1)
+				}
+				pAlive = false
+			}
+
+			if hops--; pAlive || q == nil || hops == 0 {
+				// p might already be self-linked here, but if so:
+				// - CASing head will surely fail
+				// - CASing pred's next will be useless but harmless.
+				if c != p && !queue.tryCasSuccessor(pred, c, p) || pAlive {
+					// if CAS failed or alive, abandon old pred
+					hops = maxHops
+					pred, c = p, q
+				} else {
+					c = p
+				}
+			} else if p == q {
+				continue loop
+			}
+		}
+		return
+	}
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+func (queue *JDKLinkedQueueOf[T]) ContainsFunc(match func(T) bool) bool {
+	for p := queue.first(); p != nil; p = queue.succ(p) {
+		if _p := (*linkedListNode[T])(p); _p.item() != nil && match(_p.value()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all of the elements from this queue.
+func (queue *JDKLinkedQueueOf[T]) Clear() {
+	for _, ok := queue.Poll(); ok; _, ok = queue.Poll() {
+	}
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+// The returned slice is a weakly consistent snapshot: elements added or removed concurrently
+// may or may not be included.
+func (queue *JDKLinkedQueueOf[T]) ToSlice() (values []T) {
+	for p := queue.first(); p != nil; p = queue.succ(p) {
+		if _p := (*linkedListNode[T])(p); _p.item() != nil {
+			values = append(values, _p.value())
+		}
+	}
+	return
+}
+
 // IsEmpty returns if this queue contains no elements.
-func (queue *JDKLinkedQueue) IsEmpty() bool {
+func (queue *JDKLinkedQueueOf[T]) IsEmpty() bool {
 	return queue.first() == nil
 }
 
@@ -209,12 +394,13 @@ func (queue *JDKLinkedQueue) IsEmpty() bool {
 // Additionally, if elements are added or removed during execution
 // of this method, the returned result may be inaccurate. Thus,
 // this method is typically not very useful in concurrent applications.
-func (queue *JDKLinkedQueue) Size() int32 {
+// See ApproximateSize for a constant-time estimate.
+func (queue *JDKLinkedQueueOf[T]) Size() int32 {
 loop:
 	for {
 		var count int32
 		for p := queue.first(); p != nil; {
-			_p := (*linkedListNode)(p)
+			_p := (*linkedListNode[T])(p)
 			if _p.item() != nil {
 				count++
 				if count == math.MaxInt32 {
@@ -232,43 +418,107 @@ loop:
 	}
 }
 
+// ApproximateSize returns the number of elements in this queue in constant time, if this queue
+// was created with size tracking. The result is an estimate: insertions and removals which are
+// in progress may or may not be counted. If size tracking is disabled, it falls back to Size.
+func (queue *JDKLinkedQueueOf[T]) ApproximateSize() int32 {
+	if queue.size == nil {
+		return queue.Size()
+	}
+
+	switch size := queue.size.Sum(); {
+	case size <= 0:
+		// removal might be counted before the corresponding insertion
+		return 0
+	case size >= math.MaxInt32:
+		return math.MaxInt32
+	default:
+		return int32(size)
+	}
+}
+
 // Iterator returns iterator of underlying elements.
-func (queue *JDKLinkedQueue) Iterator() Iterator {
+func (queue *JDKLinkedQueueOf[T]) Iterator() IteratorOf[T] {
 	return newJdkLinkedQueueIter(queue)
 }
 
-func (queue *JDKLinkedQueue) casTail(old, new unsafe.Pointer) bool {
+// All returns an iterator over the elements in this queue, in proper sequence, without removing them.
+//
+// The iteration is weakly consistent: it never yields an element twice, yields every element which is present
+// for the whole iteration, and may or may not yield elements offered or polled concurrently.
+// It is safe to Offer, Poll or Remove during the iteration, including from the loop body.
+func (queue *JDKLinkedQueueOf[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for p := queue.first(); p != nil; p = queue.succ(p) {
+			if _p := (*linkedListNode[T])(p); _p.item() != nil && !yield(_p.value()) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator which polls and yields the elements of this queue until it is empty.
+// Each yielded element has been removed from this queue. Elements offered concurrently are yielded
+// as long as the queue does not become empty. Stopping the iteration early leaves the remaining elements in this queue.
+func (queue *JDKLinkedQueueOf[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v, ok := queue.Poll(); ok && yield(v); v, ok = queue.Poll() {
+		}
+	}
+}
+
+func (queue *JDKLinkedQueueOf[T]) addSize(delta int64) {
+	if queue.size != nil {
+		queue.size.Add(delta)
+	}
+}
+
+func (queue *JDKLinkedQueueOf[T]) casTail(old, new unsafe.Pointer) bool {
 	return atomic.CompareAndSwapPointer(&queue.t, old, new)
 }
 
-func (queue *JDKLinkedQueue) casHead(old, new unsafe.Pointer) bool {
+func (queue *JDKLinkedQueueOf[T]) casHead(old, new unsafe.Pointer) bool {
 	return atomic.CompareAndSwapPointer(&queue.h, old, new)
 }
 
-func (queue *JDKLinkedQueue) updateHead(h, p unsafe.Pointer) {
+func (queue *JDKLinkedQueueOf[T]) updateHead(h, p unsafe.Pointer) {
 	if h != p && queue.casHead(h, p) {
-		(*linkedListNode)(h).setNext(h)
+		(*linkedListNode[T])(h).setNext(h)
 	}
 }
 
-func (queue *JDKLinkedQueue) succ(node unsafe.Pointer) unsafe.Pointer {
+// tryCasSuccessor tries to CAS pred.next (or head, if pred is nil) from c to p.
+// Caller must ensure that we're not unlinking the trailing node.
+func (queue *JDKLinkedQueueOf[T]) tryCasSuccessor(pred, c, p unsafe.Pointer) bool {
+	if pred != nil {
+		return (*linkedListNode[T])(pred).casNext(c, p)
+	}
+
+	if queue.casHead(c, p) {
+		(*linkedListNode[T])(c).setNext(c)
+		return true
+	}
+	return false
+}
+
+func (queue *JDKLinkedQueueOf[T]) succ(node unsafe.Pointer) unsafe.Pointer {
 	old := node
-	if node = (*linkedListNode)(node).next(); old == node {
+	if node = (*linkedListNode[T])(node).next(); old == node {
 		node = queue.head()
 	}
 	return node
 }
 
-type jdkLinkedQueueIter struct {
-	q        *JDKLinkedQueue
+type jdkLinkedQueueIter[T any] struct {
+	q        *JDKLinkedQueueOf[T]
 	nextNode unsafe.Pointer
 	nextItem unsafe.Pointer
-	nextVal  interface{}
+	nextVal  T
 	lastRet  unsafe.Pointer
 }
 
-func newJdkLinkedQueueIter(queue *JDKLinkedQueue) (iter *jdkLinkedQueueIter) {
-	iter = &jdkLinkedQueueIter{
+func newJdkLinkedQueueIter[T any](queue *JDKLinkedQueueOf[T]) (iter *jdkLinkedQueueIter[T]) {
+	iter = &jdkLinkedQueueIter[T]{
 		q: queue,
 	}
 
@@ -279,7 +529,7 @@ loop:
 		p := iter.q.head()
 		h := p
 		for ; ; p = q {
-			_p := (*linkedListNode)(p)
+			_p := (*linkedListNode[T])(p)
 
 			item := _p.item()
 			if item != nil {
@@ -305,21 +555,21 @@ loop:
 }
 
 // HasNext returns true if has next.
-func (i *jdkLinkedQueueIter) HasNext() bool {
+func (i *jdkLinkedQueueIter[T]) HasNext() bool {
 	return i.nextItem != nil
 }
 
 // Next return next elements. There is no guarantee that hasNext and next are atomically due to data racy.
-func (i *jdkLinkedQueueIter) Next() interface{} {
+func (i *jdkLinkedQueueIter[T]) Next() (v T) {
 	pred := i.nextNode
 	if pred == nil {
-		return nil
+		return
 	}
 
 	i.lastRet = pred
 
 	var q, item unsafe.Pointer
-	var val interface{}
+	var val T
 	for p := i.q.succ(pred); ; p = q {
 		if p == nil {
 			i.nextNode = p
@@ -331,7 +581,7 @@ func (i *jdkLinkedQueueIter) Next() interface{} {
 			return x
 		}
 
-		_p := (*linkedListNode)(p)
+		_p := (*linkedListNode[T])(p)
 		item, val = _p.item(), _p.value()
 		if item != nil {
 			i.nextNode = p
@@ -346,21 +596,127 @@ func (i *jdkLinkedQueueIter) Next() interface{} {
 		// unlink deleted nodes
 		q = i.q.succ(p)
 		if q != nil {
-			(*linkedListNode)(pred).casNext(p, q)
+			(*linkedListNode[T])(pred).casNext(p, q)
 		}
 	}
 }
 
 // Remove from the underlying collection the last element returned
 // by this iterator.
-func (i *jdkLinkedQueueIter) Remove() {
+func (i *jdkLinkedQueueIter[T]) Remove() {
 	l := i.lastRet
 	if l == nil {
 		return
 	}
 
 	// rely on a future traversal to relink.
-	_l := (*linkedListNode)(l)
-	_l.setItemNil()
+	_l := (*linkedListNode[T])(l)
+	if item := _l.item(); item != nil && _l.casItemNil(item) {
+		i.q.addSize(-1)
+	}
 	i.lastRet = nil
 }
+
+// JDKLinkedQueue represents jdk-based concurrent non blocking linked-list queue.
+// It is a thin wrapper over JDKLinkedQueueOf[interface{}], where nil indicates empty queue.
+type JDKLinkedQueue struct {
+	q *JDKLinkedQueueOf[interface{}]
+}
+
+// NewJDKLinkedQueue creates new JDKLinkedQueue.
+func NewJDKLinkedQueue() *JDKLinkedQueue {
+	return &JDKLinkedQueue{
+		q: NewJDKLinkedQueueOf[interface{}](),
+	}
+}
+
+// NewSizeTrackingJDKLinkedQueue creates new JDKLinkedQueue which tracks its number of elements.
+// See NewSizeTrackingJDKLinkedQueueOf.
+func NewSizeTrackingJDKLinkedQueue() *JDKLinkedQueue {
+	return &JDKLinkedQueue{
+		q: NewSizeTrackingJDKLinkedQueueOf[interface{}](),
+	}
+}
+
+// Offer inserts the specified element at the tail of this queue. Nil element is ignored.
+func (queue *JDKLinkedQueue) Offer(v interface{}) {
+	if v != nil {
+		queue.q.Offer(v)
+	}
+}
+
+// Poll head element.
+func (queue *JDKLinkedQueue) Poll() interface{} {
+	v, _ := queue.q.Poll()
+	return v
+}
+
+// Peek return head element
+func (queue *JDKLinkedQueue) Peek() interface{} {
+	v, _ := queue.q.Peek()
+	return v
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (queue *JDKLinkedQueue) IsEmpty() bool {
+	return queue.q.IsEmpty()
+}
+
+// Size returns the number of elements in this queue. See JDKLinkedQueueOf.Size.
+func (queue *JDKLinkedQueue) Size() int32 {
+	return queue.q.Size()
+}
+
+// ApproximateSize returns the number of elements in this queue in constant time, if this queue
+// was created with size tracking. See JDKLinkedQueueOf.ApproximateSize.
+func (queue *JDKLinkedQueue) ApproximateSize() int32 {
+	return queue.q.ApproximateSize()
+}
+
+// AddAll appends all of the given elements to the tail of this queue, in order.
+// Returns false without inserting anything if values is empty or any element is nil.
+func (queue *JDKLinkedQueue) AddAll(values ...interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return false
+		}
+	}
+	return queue.q.AddAll(values...)
+}
+
+// Remove removes a single instance of the specified element from this queue, if present.
+// Returns true if an element was removed.
+func (queue *JDKLinkedQueue) Remove(v interface{}) bool {
+	return v != nil && queue.q.RemoveFunc(func(item interface{}) bool { return item == v })
+}
+
+// Contains returns true if this queue contains the specified element.
+func (queue *JDKLinkedQueue) Contains(v interface{}) bool {
+	return v != nil && queue.q.ContainsFunc(func(item interface{}) bool { return item == v })
+}
+
+// Clear removes all of the elements from this queue.
+func (queue *JDKLinkedQueue) Clear() {
+	queue.q.Clear()
+}
+
+// ToSlice returns a slice containing all of the elements in this queue. See JDKLinkedQueueOf.ToSlice.
+func (queue *JDKLinkedQueue) ToSlice() []interface{} {
+	return queue.q.ToSlice()
+}
+
+// All returns an iterator over the elements in this queue without removing them. See JDKLinkedQueueOf.All.
+func (queue *JDKLinkedQueue) All() iter.Seq[interface{}] {
+	return queue.q.All()
+}
+
+// Drain returns an iterator which polls and yields the elements of this queue until it is empty.
+// See JDKLinkedQueueOf.Drain.
+func (queue *JDKLinkedQueue) Drain() iter.Seq[interface{}] {
+	return queue.q.Drain()
+}
+
+// Iterator returns iterator of underlying elements.
+func (queue *JDKLinkedQueue) Iterator() Iterator {
+	return queue.q.Iterator()
+}
@@ -29,6 +29,30 @@ func TestJDKLinkedQueue_Mix(t *testing.T) {
 	testMix(t, DefaultQueue(), 10, 10)
 }
 
+func TestJDKLinkedQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewQueueOf[int](JDKLinkedQueueType))
+}
+
+func TestJDKLinkedQueueOf_Iterator(t *testing.T) {
+	q := NewJDKLinkedQueueOf[int]()
+	for i := 0; i < 100; i++ {
+		q.Offer(i)
+	}
+
+	expected := 0
+	for iter := q.Iterator(); iter.HasNext(); expected++ {
+		if v := iter.Next(); v != expected {
+			t.Fatal(v, expected)
+		} else if v%2 == 0 {
+			iter.Remove()
+		}
+	}
+
+	if expected != 100 || q.Size() != 50 {
+		t.Fatal(expected, q.Size())
+	}
+}
+
 type anonymousStruct struct {
 	v int
 }
@@ -117,3 +141,153 @@ func TestJDKLinkedQueueRace(t *testing.T) {
 
 	t.Log(counter)
 }
+
+func TestJDKLinkedQueue_Collection(t *testing.T) {
+	testCollection(t, DefaultQueue())
+}
+
+func TestJDKLinkedQueueOf_RemoveIf(t *testing.T) {
+	q := NewJDKLinkedQueueOf[int]()
+	for i := 0; i < numberEle; i++ {
+		q.Offer(i)
+	}
+
+	if !q.RemoveIf(func(v int) bool { return v%3 != 0 }) || q.RemoveIf(func(v int) bool { return v%3 != 0 }) {
+		t.Fatal()
+	}
+
+	expected := 0
+	for _, v := range q.ToSlice() {
+		if v != expected {
+			t.Fatal(v, expected)
+		}
+		expected += 3
+	}
+
+	if int(q.Size()) != (numberEle+2)/3 {
+		t.Fatal(q.Size())
+	}
+
+	// removing everything leaves an empty but usable queue
+	q.RemoveIf(func(int) bool { return true })
+	if !q.IsEmpty() || !q.Offer(1) || q.Size() != 1 {
+		t.Fatal()
+	}
+}
+
+func TestJDKLinkedQueueOf_ConcurrentBulk(t *testing.T) {
+	q := NewJDKLinkedQueueOf[int]()
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.AddAll(p, -1, p)
+				if i%10 == 0 {
+					q.RemoveIf(func(v int) bool { return v < 0 })
+				}
+				q.RemoveFunc(func(v int) bool { return v == p })
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	q.RemoveIf(func(v int) bool { return v < 0 })
+	if s := q.ToSlice(); len(s) != 4000 || q.ContainsFunc(func(v int) bool { return v < 0 }) {
+		t.Fatal(len(s))
+	}
+}
+
+func TestJDKLinkedQueueOf_All(t *testing.T) {
+	testWeaklyConsistentOf(t, NewJDKLinkedQueueOf[int]())
+
+	// removing from the loop body is allowed
+	q := NewJDKLinkedQueue()
+	q.AddAll(1, 2, 3, 4)
+	for v := range q.All() {
+		q.Remove(v)
+	}
+	if !q.IsEmpty() {
+		t.Fatal(q.ToSlice())
+	}
+}
+
+func TestJDKLinkedQueueOf_Drain(t *testing.T) {
+	testDrainOf(t, NewJDKLinkedQueueOf[int]())
+}
+
+func TestJDKLinkedQueueOf_ApproximateSize(t *testing.T) {
+	q := NewSizeTrackingJDKLinkedQueueOf[int]()
+	check := func(expected int32) {
+		t.Helper()
+		if q.ApproximateSize() != expected || q.Size() != expected {
+			t.Fatal(q.ApproximateSize(), q.Size(), expected)
+		}
+	}
+
+	check(0)
+	q.Offer(0)
+	q.AddAll(1, 2, 3, 4, 5, 6, 7)
+	check(8)
+
+	q.Poll()
+	q.RemoveFunc(func(v int) bool { return v == 3 })
+	q.RemoveIf(func(v int) bool { return v > 5 })
+	check(4)
+
+	iter := q.Iterator()
+	iter.Next()
+	iter.Remove()
+	iter.Remove() // no-op
+	check(3)
+
+	for range q.Drain() {
+		break
+	}
+	check(2)
+
+	q.Clear()
+	check(0)
+
+	// untracked queue falls back to Size
+	untracked := NewJDKLinkedQueue()
+	untracked.Offer(1)
+	if untracked.ApproximateSize() != 1 {
+		t.Fatal()
+	}
+}
+
+func TestJDKLinkedQueue_ApproximateSizeConcurrent(t *testing.T) {
+	q := NewSizeTrackingJDKLinkedQueue()
+
+	// values are shared between goroutines, so a Remove may lose to
+	// another goroutine's Poll; count what actually left the queue
+	var offered, removed atomic.Int32
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.Offer(i)
+				q.AddAll(i, i)
+				offered.Add(3)
+				if i%2 == p%2 {
+					if q.Poll() != nil {
+						removed.Add(1)
+					}
+				} else if q.Remove(i) {
+					removed.Add(1)
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	expected := offered.Load() - removed.Load()
+	if q.ApproximateSize() != expected || q.Size() != expected {
+		t.Fatal(q.ApproximateSize(), q.Size(), expected)
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type blockingNode[T any] struct {
+	v       T
+	next    *blockingNode[T]
+	removed bool // unlinked from the interior of queue
+}
+
+// LinkedBlockingQueueOf is an optionally-bounded blocking queue based on linked nodes,
+// ported from OpenJDK LinkedBlockingQueue.
+//
+// The queue uses the "two lock queue" algorithm: one lock guards insertion at the tail and
+// another guards removal at the head, so producers and consumers can proceed in parallel.
+// Blocked routines are woken up through signaling channels, so waiting can be cancelled by context.
+type LinkedBlockingQueueOf[T any] struct {
+	capacity int32
+	count    int32
+
+	head *blockingNode[T] // head.v is always zero value
+	last *blockingNode[T] // last.next is always nil
+
+	takeLock sync.Mutex
+	notEmpty chan struct{}
+
+	putLock sync.Mutex
+	notFull chan struct{}
+}
+
+// NewLinkedBlockingQueueOf creates new LinkedBlockingQueueOf with the given capacity.
+// If capacity is not positive, math.MaxInt32 is used.
+func NewLinkedBlockingQueueOf[T any](capacity int32) *LinkedBlockingQueueOf[T] {
+	if capacity <= 0 {
+		capacity = math.MaxInt32
+	}
+
+	q := &LinkedBlockingQueueOf[T]{
+		capacity: capacity,
+		head:     &blockingNode[T]{},
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+	q.last = q.head
+	return q
+}
+
+// signal wakes up one routine waiting on the given channel. The signal is kept if no one is waiting,
+// thus a routine which is about to wait will not miss it.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (q *LinkedBlockingQueueOf[T]) enqueue(node *blockingNode[T]) {
+	q.last.next = node
+	q.last = node
+}
+
+func (q *LinkedBlockingQueueOf[T]) dequeue() (v T) {
+	h := q.head
+	first := h.next
+	h.next = h // help iterators to detect dequeued node
+	q.head = first
+
+	var zero T
+	v, first.v = first.v, zero
+	return
+}
+
+func (q *LinkedBlockingQueueOf[T]) fullyLock() {
+	q.putLock.Lock()
+	q.takeLock.Lock()
+}
+
+func (q *LinkedBlockingQueueOf[T]) fullyUnlock() {
+	q.takeLock.Unlock()
+	q.putLock.Unlock()
+}
+
+// Put inserts the specified element at the tail of this queue, waiting if necessary for space to become available.
+// Returns ctx.Err() if ctx is done before the element could be inserted.
+func (q *LinkedBlockingQueueOf[T]) Put(ctx context.Context, v T) error {
+	node := &blockingNode[T]{v: v}
+
+	q.putLock.Lock()
+	for atomic.LoadInt32(&q.count) == q.capacity {
+		q.putLock.Unlock()
+		select {
+		case <-q.notFull:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.putLock.Lock()
+	}
+
+	q.enqueue(node)
+	c := atomic.AddInt32(&q.count, 1)
+	if c < q.capacity {
+		signal(q.notFull)
+	}
+	q.putLock.Unlock()
+
+	if c == 1 {
+		signal(q.notEmpty)
+	}
+	return nil
+}
+
+// Offer inserts the specified element at the tail of this queue if it is possible to do so immediately
+// without exceeding the capacity. Returns false if this queue is full.
+func (q *LinkedBlockingQueueOf[T]) Offer(v T) bool {
+	if atomic.LoadInt32(&q.count) == q.capacity {
+		return false
+	}
+
+	node := &blockingNode[T]{v: v}
+
+	c := int32(-1)
+	q.putLock.Lock()
+	if atomic.LoadInt32(&q.count) < q.capacity {
+		q.enqueue(node)
+		if c = atomic.AddInt32(&q.count, 1); c < q.capacity {
+			signal(q.notFull)
+		}
+	}
+	q.putLock.Unlock()
+
+	if c == 1 {
+		signal(q.notEmpty)
+	}
+	return c > 0
+}
+
+// OfferTimeout inserts the specified element at the tail of this queue, waiting up to the specified
+// timeout if necessary for space to become available. Returns false if the timeout elapses before space is available.
+func (q *LinkedBlockingQueueOf[T]) OfferTimeout(v T, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Put(ctx, v) == nil
+}
+
+// Take retrieves and removes the head of this queue, waiting if necessary until an element becomes available.
+// Returns ctx.Err() if ctx is done before an element is available.
+func (q *LinkedBlockingQueueOf[T]) Take(ctx context.Context) (v T, err error) {
+	q.takeLock.Lock()
+	for atomic.LoadInt32(&q.count) == 0 {
+		q.takeLock.Unlock()
+		select {
+		case <-q.notEmpty:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		q.takeLock.Lock()
+	}
+
+	v = q.dequeue()
+	c := atomic.AddInt32(&q.count, -1)
+	if c > 0 {
+		signal(q.notEmpty)
+	}
+	q.takeLock.Unlock()
+
+	if c == q.capacity-1 {
+		signal(q.notFull)
+	}
+	return
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+func (q *LinkedBlockingQueueOf[T]) Poll() (v T, ok bool) {
+	if atomic.LoadInt32(&q.count) == 0 {
+		return
+	}
+
+	c := int32(-1)
+	q.takeLock.Lock()
+	if atomic.LoadInt32(&q.count) > 0 {
+		v, ok = q.dequeue(), true
+		if c = atomic.AddInt32(&q.count, -1); c > 0 {
+			signal(q.notEmpty)
+		}
+	}
+	q.takeLock.Unlock()
+
+	if c == q.capacity-1 {
+		signal(q.notFull)
+	}
+	return
+}
+
+// PollTimeout retrieves and removes the head of this queue, waiting up to the specified timeout
+// if necessary for an element to become available. Returns false if the timeout elapses before an element is available.
+func (q *LinkedBlockingQueueOf[T]) PollTimeout(timeout time.Duration) (v T, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	v, err := q.Take(ctx)
+	return v, err == nil
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+func (q *LinkedBlockingQueueOf[T]) Peek() (v T, ok bool) {
+	if atomic.LoadInt32(&q.count) == 0 {
+		return
+	}
+
+	q.takeLock.Lock()
+	if atomic.LoadInt32(&q.count) > 0 {
+		v, ok = q.head.next.v, true
+	}
+	q.takeLock.Unlock()
+	return
+}
+
+// DrainTo removes at most n available elements from this queue and returns them in order.
+// If n is negative, all available elements are removed.
+func (q *LinkedBlockingQueueOf[T]) DrainTo(n int) (drained []T) {
+	if n < 0 {
+		n = math.MaxInt32
+	}
+	if n == 0 {
+		return
+	}
+
+	q.takeLock.Lock()
+	c := atomic.LoadInt32(&q.count)
+	if int(c) < n {
+		n = int(c)
+	}
+
+	drained = make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		drained = append(drained, q.dequeue())
+	}
+
+	if c = atomic.AddInt32(&q.count, -int32(n)); c > 0 {
+		signal(q.notEmpty)
+	}
+	q.takeLock.Unlock()
+
+	if n > 0 && c+int32(n) == q.capacity {
+		signal(q.notFull)
+	}
+	return
+}
+
+// Size returns the number of elements in this queue.
+func (q *LinkedBlockingQueueOf[T]) Size() int32 {
+	return atomic.LoadInt32(&q.count)
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *LinkedBlockingQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Capacity returns the capacity of this queue.
+func (q *LinkedBlockingQueueOf[T]) Capacity() int32 {
+	return q.capacity
+}
+
+// RemainingCapacity returns the number of additional elements that this queue can ideally
+// accept without blocking. Note that this value could be inaccurate because of concurrent updates.
+func (q *LinkedBlockingQueueOf[T]) RemainingCapacity() int32 {
+	return q.capacity - atomic.LoadInt32(&q.count)
+}
+
+// Iterator returns iterator of underlying elements. The iterator is weakly consistent:
+// it never returns an element twice but may or may not reflect concurrent updates.
+func (q *LinkedBlockingQueueOf[T]) Iterator() IteratorOf[T] {
+	iter := &linkedBlockingQueueIter[T]{q: q}
+
+	q.fullyLock()
+	if iter.next = q.head.next; iter.next != nil {
+		iter.nextVal = iter.next.v
+	}
+	q.fullyUnlock()
+
+	return iter
+}
+
+// succ returns the successor of node, or head.next if node has been dequeued.
+// Must be called under fullyLock.
+func (q *LinkedBlockingQueueOf[T]) succ(node *blockingNode[T]) *blockingNode[T] {
+	if node.next == node {
+		return q.head.next
+	}
+	return node.next
+}
+
+// unlink interior node p with predecessor trail. Must be called under fullyLock.
+func (q *LinkedBlockingQueueOf[T]) unlink(p, trail *blockingNode[T]) {
+	var zero T
+	p.v, p.removed = zero, true
+
+	trail.next = p.next
+	if q.last == p {
+		q.last = trail
+	}
+
+	if atomic.AddInt32(&q.count, -1) == q.capacity-1 {
+		signal(q.notFull)
+	}
+}
+
+type linkedBlockingQueueIter[T any] struct {
+	q       *LinkedBlockingQueueOf[T]
+	next    *blockingNode[T]
+	nextVal T
+	lastRet *blockingNode[T]
+}
+
+// HasNext returns true if has next.
+func (i *linkedBlockingQueueIter[T]) HasNext() bool {
+	return i.next != nil
+}
+
+// Next return next elements.
+func (i *linkedBlockingQueueIter[T]) Next() (v T) {
+	i.q.fullyLock()
+	defer i.q.fullyUnlock()
+
+	p := i.next
+	if p == nil {
+		return
+	}
+
+	v, i.lastRet = i.nextVal, p
+
+	for p = i.q.succ(p); p != nil && p.removed; p = i.q.succ(p) {
+	}
+
+	var zero T
+	if i.next, i.nextVal = p, zero; p != nil {
+		i.nextVal = p.v
+	}
+	return
+}
+
+// Remove from the underlying collection the last element returned
+// by this iterator.
+func (i *linkedBlockingQueueIter[T]) Remove() {
+	l := i.lastRet
+	if l == nil {
+		return
+	}
+	i.lastRet = nil
+
+	i.q.fullyLock()
+	for trail, p := i.q.head, i.q.head.next; p != nil; trail, p = p, p.next {
+		if p == l {
+			i.q.unlink(p, trail)
+			break
+		}
+	}
+	i.q.fullyUnlock()
+}
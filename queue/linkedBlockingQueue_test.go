@@ -0,0 +1,228 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLinkedBlockingQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewLinkedBlockingQueueOf[int](numberEle))
+}
+
+func TestLinkedBlockingQueueOf_Capacity(t *testing.T) {
+	q := NewLinkedBlockingQueueOf[int](2)
+	if !q.Offer(1) || !q.Offer(2) || q.Offer(3) {
+		t.Fatal()
+	}
+
+	if q.RemainingCapacity() != 0 || q.Size() != 2 || q.Capacity() != 2 {
+		t.Fatal(q.RemainingCapacity(), q.Size())
+	}
+
+	if q.OfferTimeout(3, 20*time.Millisecond) {
+		t.Fatal()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Put(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+
+	if v, ok := q.Poll(); !ok || v != 1 || q.RemainingCapacity() != 1 {
+		t.Fatal(v, ok)
+	}
+
+	if NewLinkedBlockingQueueOf[int](0).Capacity() <= 0 {
+		t.Fatal()
+	}
+}
+
+func TestLinkedBlockingQueueOf_Blocking(t *testing.T) {
+	q := NewLinkedBlockingQueueOf[int](1)
+
+	if _, ok := q.PollTimeout(20 * time.Millisecond); ok {
+		t.Fatal()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.Take(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	// blocked Take is woken up by Put
+	done := make(chan int)
+	go func() {
+		v, err := q.Take(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := q.Put(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-done; v != 7 {
+		t.Fatal(v)
+	}
+
+	// blocked Put is woken up by Take
+	_ = q.Put(context.Background(), 1)
+	go func() {
+		done <- 0
+		if err := q.Put(context.Background(), 2); err != nil {
+			t.Error(err)
+		}
+		done <- 2
+	}()
+
+	<-done
+	time.Sleep(50 * time.Millisecond)
+	if v, ok := q.PollTimeout(time.Second); !ok || v != 1 {
+		t.Fatal(v, ok)
+	}
+	<-done
+	if v, ok := q.Peek(); !ok || v != 2 {
+		t.Fatal(v, ok)
+	}
+}
+
+func TestLinkedBlockingQueueOf_ProducerConsumer(t *testing.T) {
+	q := NewLinkedBlockingQueueOf[int](16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxNumberProducer; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numberEle; j++ {
+				if err := q.Put(context.Background(), j); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	counts := make([]int, numberEle)
+	var mu sync.Mutex
+
+	var consumers sync.WaitGroup
+	for i := 0; i < maxNumberProducer; i++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for j := 0; j < numberEle; j++ {
+				v, err := q.Take(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				counts[v]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	consumers.Wait()
+
+	for i := range counts {
+		if counts[i] != maxNumberProducer {
+			t.Fatal(i, counts[i])
+		}
+	}
+
+	if !q.IsEmpty() || q.RemainingCapacity() != 16 {
+		t.Fatal(q.Size())
+	}
+}
+
+func TestLinkedBlockingQueueOf_DrainTo(t *testing.T) {
+	q := NewLinkedBlockingQueueOf[int](10)
+	for i := 0; i < 10; i++ {
+		q.Offer(i)
+	}
+
+	if drained := q.DrainTo(0); len(drained) != 0 {
+		t.Fatal(drained)
+	}
+
+	drained := q.DrainTo(4)
+	if len(drained) != 4 || drained[0] != 0 || drained[3] != 3 || q.Size() != 6 {
+		t.Fatal(drained, q.Size())
+	}
+
+	drained = q.DrainTo(-1)
+	if len(drained) != 6 || drained[0] != 4 || drained[5] != 9 || !q.IsEmpty() {
+		t.Fatal(drained, q.Size())
+	}
+
+	// queue is still usable after drained
+	if !q.Offer(10) || q.Size() != 1 {
+		t.Fatal()
+	}
+}
+
+func TestLinkedBlockingQueueOf_Iterator(t *testing.T) {
+	q := NewLinkedBlockingQueueOf[int](100)
+	for i := 0; i < 100; i++ {
+		q.Offer(i)
+	}
+
+	expected := 0
+	for iter := q.Iterator(); iter.HasNext(); expected++ {
+		if v := iter.Next(); v != expected {
+			t.Fatal(v, expected)
+		} else if v%2 == 0 {
+			iter.Remove()
+		}
+
+		// concurrent poll does not break iteration
+		if expected == 10 {
+			q.Poll()
+		}
+	}
+
+	if expected != 100 || q.Size() != 49 || q.RemainingCapacity() != 51 {
+		t.Fatal(expected, q.Size())
+	}
+
+	if v, ok := q.Poll(); !ok || v != 3 {
+		t.Fatal(v, ok)
+	}
+
+	// remove last element then append
+	iter := q.Iterator()
+	for iter.HasNext() {
+		iter.Next()
+	}
+	iter.Remove()
+	q.Offer(100)
+
+	drained := q.DrainTo(-1)
+	if len(drained) != 48 || drained[len(drained)-2] != 97 || drained[len(drained)-1] != 100 {
+		t.Fatal(drained)
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// slotBusy marks a slot which is exclusively held by a consumer or a peeker.
+// Cursors never reach this bit in practice.
+const slotBusy = uint64(1) << 62
+
+type arraySlot[T any] struct {
+	seq uint64
+	v   T
+}
+
+// MPMCArrayQueueOf is a lock-free, bounded, multi-producer multi-consumer array queue
+// based on Dmitry Vyukov's bounded MPMC queue, similar to JCTools MpmcArrayQueue.
+//
+// Each slot carries a sequence number which tells producers and consumers whether the slot
+// is ready to be written or read in the current lap, so elements are stored without
+// allocating any node per Offer.
+type MPMCArrayQueueOf[T any] struct {
+	// The padding members below are here to ensure each cursor is on a separate cache line.
+	// This prevents false sharing and hence improves performance.
+	_          internal.CacheLinePad
+	enqueuePos uint64
+	_          internal.CacheLinePad
+	dequeuePos uint64
+	_          internal.CacheLinePad
+	mask       uint64
+	buffer     []arraySlot[T]
+	removal    sync.Mutex // serializes RemoveFunc
+}
+
+// NewMPMCArrayQueueOf creates new MPMCArrayQueueOf. Capacity is rounded up to the next power of two,
+// and at least 2.
+func NewMPMCArrayQueueOf[T any](capacity int32) *MPMCArrayQueueOf[T] {
+	size := roundUpPowerOf2(capacity)
+
+	q := &MPMCArrayQueueOf[T]{
+		mask:   uint64(size - 1),
+		buffer: make([]arraySlot[T], size),
+	}
+	for i := range q.buffer {
+		q.buffer[i].seq = uint64(i)
+	}
+	return q
+}
+
+func roundUpPowerOf2(v int32) int32 {
+	n := int32(2)
+	for n < v && n < 1<<30 {
+		n <<= 1
+	}
+	return n
+}
+
+// Offer inserts the specified element at the tail of this queue. Returns false if this queue is full.
+func (q *MPMCArrayQueueOf[T]) Offer(v T) bool {
+	var slot *arraySlot[T]
+
+	pos := atomic.LoadUint64(&q.enqueuePos)
+	for {
+		slot = &q.buffer[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		if dif := int64(seq) - int64(pos); dif == 0 {
+			if atomic.CompareAndSwapUint64(&q.enqueuePos, pos, pos+1) {
+				break
+			}
+		} else if dif < 0 {
+			// slot is still occupied by previous lap
+			return false
+		} else if seq&slotBusy != 0 {
+			runtime.Gosched()
+		}
+		pos = atomic.LoadUint64(&q.enqueuePos)
+	}
+
+	slot.v = v
+	atomic.StoreUint64(&slot.seq, pos+1)
+	return true
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+func (q *MPMCArrayQueueOf[T]) Poll() (v T, ok bool) {
+	var slot *arraySlot[T]
+
+	pos := atomic.LoadUint64(&q.dequeuePos)
+	for {
+		slot = &q.buffer[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		if dif := int64(seq) - int64(pos+1); dif == 0 {
+			if atomic.CompareAndSwapUint64(&q.dequeuePos, pos, pos+1) {
+				break
+			}
+		} else if dif < 0 {
+			// slot has not been written in this lap
+			return
+		} else if seq&slotBusy != 0 {
+			runtime.Gosched()
+		}
+		pos = atomic.LoadUint64(&q.dequeuePos)
+	}
+
+	// wait for concurrent peeker to leave the slot
+	for !atomic.CompareAndSwapUint64(&slot.seq, pos+1, (pos+1)|slotBusy) {
+		runtime.Gosched()
+	}
+
+	var zero T
+	v, slot.v, ok = slot.v, zero, true
+	atomic.StoreUint64(&slot.seq, pos+q.mask+1)
+	return
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+func (q *MPMCArrayQueueOf[T]) Peek() (v T, ok bool) {
+	for {
+		pos := atomic.LoadUint64(&q.dequeuePos)
+		slot := &q.buffer[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		if dif := int64(seq) - int64(pos+1); dif == 0 {
+			// hold the slot so that consumers could not clear it while reading
+			if atomic.CompareAndSwapUint64(&slot.seq, seq, seq|slotBusy) {
+				v, ok = slot.v, true
+				atomic.StoreUint64(&slot.seq, seq)
+				return
+			}
+		} else if dif < 0 {
+			return
+		} else if seq&slotBusy != 0 {
+			runtime.Gosched()
+		}
+	}
+}
+
+// Size returns the number of elements in this queue. The returned value could be inaccurate
+// because of concurrent updates.
+func (q *MPMCArrayQueueOf[T]) Size() int32 {
+	return boundedSize(&q.enqueuePos, &q.dequeuePos, q.mask+1)
+}
+
+// boundedSize returns the distance between producer and consumer cursors, in range [0, capacity].
+func boundedSize(producerIndex, consumerIndex *uint64, capacity uint64) int32 {
+	for {
+		before := atomic.LoadUint64(consumerIndex)
+		pos := atomic.LoadUint64(producerIndex)
+		if after := atomic.LoadUint64(consumerIndex); before == after {
+			if size := int64(pos - after); size > 0 {
+				if size > int64(capacity) {
+					size = int64(capacity)
+				}
+				return int32(size)
+			}
+			return 0
+		}
+	}
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *MPMCArrayQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Capacity returns the capacity of this queue.
+func (q *MPMCArrayQueueOf[T]) Capacity() int32 {
+	return int32(q.mask + 1)
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed.
+//
+// Slots are held from the head up to the matching element, which blocks consumers during the traversal.
+// The elements which are not claimed by consumers yet are then shifted by one towards the tail, and the first
+// of their slots is released as polled, thus the order of remaining elements is kept. If the matching element
+// is claimed by a consumer meanwhile, the traversal is retried. Concurrent calls of RemoveFunc are serialized.
+func (q *MPMCArrayQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	q.removal.Lock()
+	defer q.removal.Unlock()
+
+	for {
+		head := atomic.LoadUint64(&q.dequeuePos)
+
+		pos, found, consumed := head, false, false
+		for ; ; pos++ {
+			if dif := holdSlot(&q.buffer[pos&q.mask], pos); dif != 0 {
+				consumed = dif > 0
+				break
+			}
+			if found = match(q.buffer[pos&q.mask].v); found {
+				break
+			}
+		}
+
+		if found {
+			// consumers waiting for slots in [head, claimed) get their elements as is
+			for claimed := atomic.LoadUint64(&q.dequeuePos); claimed <= pos; claimed = atomic.LoadUint64(&q.dequeuePos) {
+				if atomic.CompareAndSwapUint64(&q.dequeuePos, claimed, claimed+1) {
+					q.shiftOut(head, claimed, pos)
+					return true
+				}
+			}
+			pos++
+		}
+		for p := head; p < pos; p++ {
+			atomic.StoreUint64(&q.buffer[p&q.mask].seq, p+1)
+		}
+
+		if !found && !consumed {
+			return false
+		}
+	}
+}
+
+// shiftOut removes the element at pos by shifting elements in [claimed, pos) by one towards the tail, then
+// releases all of the held slots in [head, pos]. The slot at claimed must have been claimed by the caller.
+func (q *MPMCArrayQueueOf[T]) shiftOut(head, claimed, pos uint64) {
+	for p := pos; p > claimed; p-- {
+		q.buffer[p&q.mask].v = q.buffer[(p-1)&q.mask].v
+		atomic.StoreUint64(&q.buffer[p&q.mask].seq, p+1)
+	}
+
+	var zero T
+	q.buffer[claimed&q.mask].v = zero
+	atomic.StoreUint64(&q.buffer[claimed&q.mask].seq, claimed+q.mask+1)
+
+	for p := head; p < claimed; p++ {
+		atomic.StoreUint64(&q.buffer[p&q.mask].seq, p+1)
+	}
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+func (q *MPMCArrayQueueOf[T]) ContainsFunc(match func(T) bool) (found bool) {
+	q.forEach(func(v T) bool {
+		found = match(v)
+		return !found
+	})
+	return
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+//
+// The traversal is weakly consistent: elements polled concurrently are skipped,
+// and elements offered after the traversal starts are not included.
+func (q *MPMCArrayQueueOf[T]) ToSlice() (values []T) {
+	q.forEach(func(v T) bool {
+		values = append(values, v)
+		return true
+	})
+	return
+}
+
+// forEach yields elements from head to tail, holding one slot at a time.
+func (q *MPMCArrayQueueOf[T]) forEach(yield func(T) bool) {
+	end := atomic.LoadUint64(&q.enqueuePos)
+	for pos := atomic.LoadUint64(&q.dequeuePos); pos < end; pos++ {
+		slot := &q.buffer[pos&q.mask]
+
+		dif := holdSlot(slot, pos)
+		if dif < 0 {
+			return
+		}
+		if dif == 0 {
+			v := slot.v
+			atomic.StoreUint64(&slot.seq, pos+1)
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// holdSlot marks the slot as busy if it holds the element of the given position. Returns 0 if the slot is held,
+// negative value if the element is not yet published, or positive value if it has been polled already.
+func holdSlot[T any](slot *arraySlot[T], pos uint64) int64 {
+	for {
+		seq := atomic.LoadUint64(&slot.seq)
+		if seq == pos+1 {
+			if atomic.CompareAndSwapUint64(&slot.seq, seq, seq|slotBusy) {
+				return 0
+			}
+		} else if seq == (pos+1)|slotBusy {
+			runtime.Gosched()
+		} else {
+			return int64(seq&^slotBusy) - int64(pos+1)
+		}
+	}
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken the same way as ToSlice. Remove of the iterator does nothing, use RemoveFunc instead.
+func (q *MPMCArrayQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(q.ToSlice())
+}
+
+// MPMCArrayQueue is a lock-free, bounded, multi-producer multi-consumer array queue.
+// It is a thin wrapper over MPMCArrayQueueOf[interface{}], where nil indicates empty queue.
+type MPMCArrayQueue struct {
+	q *MPMCArrayQueueOf[interface{}]
+}
+
+// NewMPMCArrayQueue creates new MPMCArrayQueue. Capacity is rounded up to the next power of two,
+// and at least 2.
+func NewMPMCArrayQueue(capacity int32) *MPMCArrayQueue {
+	return &MPMCArrayQueue{
+		q: NewMPMCArrayQueueOf[interface{}](capacity),
+	}
+}
+
+// Offer inserts the specified element at the tail of this queue. The element is dropped if this queue
+// is full, nil element is ignored. See TryOffer.
+func (queue *MPMCArrayQueue) Offer(v interface{}) {
+	queue.TryOffer(v)
+}
+
+// TryOffer inserts the specified element at the tail of this queue. Returns false if this queue is full
+// or element is nil.
+func (queue *MPMCArrayQueue) TryOffer(v interface{}) bool {
+	return v != nil && queue.q.Offer(v)
+}
+
+// Poll retrieves and removes the head of this queue, or returns nil if this queue is empty.
+func (queue *MPMCArrayQueue) Poll() interface{} {
+	v, _ := queue.q.Poll()
+	return v
+}
+
+// Peek retrieves, but does not remove, the head of this queue, or returns nil if this queue is empty.
+func (queue *MPMCArrayQueue) Peek() interface{} {
+	v, _ := queue.q.Peek()
+	return v
+}
+
+// Size returns the number of elements in this queue.
+func (queue *MPMCArrayQueue) Size() int32 {
+	return queue.q.Size()
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (queue *MPMCArrayQueue) IsEmpty() bool {
+	return queue.q.IsEmpty()
+}
+
+// Capacity returns the capacity of this queue.
+func (queue *MPMCArrayQueue) Capacity() int32 {
+	return queue.q.Capacity()
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence.
+// Remove of the iterator does nothing, use Remove of this queue instead.
+func (queue *MPMCArrayQueue) Iterator() Iterator {
+	return queue.q.Iterator()
+}
+
+// AddAll inserts all of the given elements at the tail of this queue, in order, stopping at the
+// first one which could not be inserted. Returns false if values is empty or not all elements were inserted.
+func (queue *MPMCArrayQueue) AddAll(values ...interface{}) bool {
+	return offerAll(queue.TryOffer, values)
+}
+
+// Remove removes a single instance of the specified element from this queue, if present.
+// Returns true if an element was removed. See MPMCArrayQueueOf.RemoveFunc.
+func (queue *MPMCArrayQueue) Remove(v interface{}) bool {
+	return v != nil && queue.q.RemoveFunc(func(item interface{}) bool { return item == v })
+}
+
+// Contains returns true if this queue contains the specified element.
+func (queue *MPMCArrayQueue) Contains(v interface{}) bool {
+	return v != nil && queue.q.ContainsFunc(func(item interface{}) bool { return item == v })
+}
+
+// Clear polls all of the elements from this queue.
+func (queue *MPMCArrayQueue) Clear() {
+	for queue.Poll() != nil {
+	}
+}
+
+// ToSlice returns a slice containing all of the elements in this queue. See MPMCArrayQueueOf.ToSlice.
+func (queue *MPMCArrayQueue) ToSlice() []interface{} {
+	return queue.q.ToSlice()
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMPMCArrayQueue_Producer(t *testing.T) {
+	testProducer(t, NewBoundedQueue(MPMCArrayQueueType, maxNumberProducer*numberEle))
+}
+
+func TestMPMCArrayQueue_Mix(t *testing.T) {
+	testMix(t, NewBoundedQueue(MPMCArrayQueueType, 50*numberEle), 50, 50)
+}
+
+func TestMPMCArrayQueue_TryOffer(t *testing.T) {
+	q := NewBoundedQueue(MPMCArrayQueueType, 2).(BoundedQueue)
+	if q.TryOffer(nil) || !q.TryOffer(1) || !q.TryOffer(2) || q.TryOffer(3) {
+		t.Fatal()
+	}
+
+	// Offer drops element when queue is full
+	q.Offer(3)
+	if q.Size() != 2 || q.Poll() != 1 || q.Poll() != 2 || q.Poll() != nil {
+		t.Fatal()
+	}
+}
+
+func TestMPMCArrayQueue_Collection(t *testing.T) {
+	testCollection(t, NewQueue(MPMCArrayQueueType))
+}
+
+func TestMPMCArrayQueueOf_RemoveFunc(t *testing.T) {
+	q := NewMPMCArrayQueueOf[*ele](64)
+
+	var wg sync.WaitGroup
+	var producing int32 = maxNumberProducer
+	seen := make([]int32, maxNumberProducer*numberEle)
+	mark := func(e *ele) {
+		if atomic.AddInt32(&seen[e.key*numberEle+e.value], 1) != 1 {
+			t.Error(e)
+		}
+	}
+
+	for i := 0; i < maxNumberProducer; i++ {
+		wg.Add(1)
+		go func(producer int) {
+			defer wg.Done()
+			defer atomic.AddInt32(&producing, -1)
+			for j := 0; j < numberEle; {
+				if q.Offer(&ele{key: producer, value: j}) {
+					j++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(i)
+	}
+
+	// every element is either polled or removed, exactly once
+	for i := 0; i < 2; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&producing) > 0 || !q.IsEmpty() {
+				if e, ok := q.Poll(); ok {
+					mark(e)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&producing) > 0 || !q.IsEmpty() {
+				// the last matching element is the removed one
+				var removed *ele
+				if q.RemoveFunc(func(e *ele) bool {
+					removed = e
+					return e.value%3 == 0
+				}) {
+					mark(removed)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, n := range seen {
+		if n != 1 {
+			t.Fatal(i, n)
+		}
+	}
+}
+
+func TestMPMCArrayQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewBoundedQueueOf[int](MPMCArrayQueueType, numberEle))
+}
+
+func TestMPMCArrayQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewMPMCArrayQueueOf[int](16), true)
+
+	q := NewMPMCArrayQueue(16)
+	q.Offer(1)
+	if iter := q.Iterator(); !iter.HasNext() || iter.Next() != 1 || iter.HasNext() {
+		t.Fatal()
+	}
+}
+
+func TestMPMCArrayQueueOf_Capacity(t *testing.T) {
+	if NewMPMCArrayQueueOf[int](0).Capacity() != 2 || NewMPMCArrayQueueOf[int](5).Capacity() != 8 ||
+		NewQueue(MPMCArrayQueueType).(*MPMCArrayQueue).Capacity() != DefaultCapacity {
+		t.Fatal()
+	}
+
+	q := NewMPMCArrayQueueOf[int](4)
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 4; i++ {
+			if !q.Offer(lap*4 + i) {
+				t.Fatal(lap, i)
+			}
+		}
+
+		if q.Offer(-1) || q.Size() != 4 {
+			t.Fatal(q.Size())
+		}
+
+		for i := 0; i < 4; i++ {
+			if v, ok := q.Poll(); !ok || v != lap*4+i {
+				t.Fatal(v, ok)
+			}
+		}
+
+		if _, ok := q.Poll(); ok || !q.IsEmpty() {
+			t.Fatal()
+		}
+	}
+}
+
+func TestMPMCArrayQueueOf_PeekRace(t *testing.T) {
+	q := NewMPMCArrayQueueOf[*ele](64)
+
+	var polled int32
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(producer int) {
+			defer wg.Done()
+			for j := 0; j < numberEle; {
+				if q.Offer(&ele{key: producer, value: j}) {
+					j++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&polled) < 4*numberEle {
+				if _, ok := q.Poll(); ok {
+					atomic.AddInt32(&polled, 1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&polled) < 4*numberEle {
+				if v, ok := q.Peek(); ok && v == nil {
+					t.Error("peeked cleared slot")
+					return
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !q.IsEmpty() {
+		t.Fatal(q.Size())
+	}
+}
+
+func TestMPMCArrayQueue_AddAll(t *testing.T) {
+	q := NewBoundedQueue(MPMCArrayQueueType, 2)
+	if q.AddAll(1, 2, 3) || q.Size() != 2 {
+		t.Fatal()
+	}
+	if s := q.ToSlice(); len(s) != 2 || s[0] != 1 || s[1] != 2 {
+		t.Fatal(s)
+	}
+
+	q.Clear()
+	if !q.IsEmpty() {
+		t.Fatal()
+	}
+}
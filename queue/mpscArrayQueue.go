@@ -0,0 +1,172 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync/atomic"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// MPSCArrayQueueOf is a lock-free, bounded, multi-producer single-consumer array queue.
+//
+// Producers claim slots the same way as MPMCArrayQueueOf, while the single consumer
+// advances its cursor without CAS.
+//
+// Poll and Peek must be called from a single routine. Other methods are safe for concurrent use.
+type MPSCArrayQueueOf[T any] struct {
+	// The padding members below are here to ensure each cursor is on a separate cache line.
+	// This prevents false sharing and hence improves performance.
+	_          internal.CacheLinePad
+	enqueuePos uint64
+	_          internal.CacheLinePad
+	dequeuePos uint64
+	_          internal.CacheLinePad
+	mask       uint64
+	buffer     []arraySlot[T]
+}
+
+// NewMPSCArrayQueueOf creates new MPSCArrayQueueOf. Capacity is rounded up to the next power of two,
+// and at least 2.
+func NewMPSCArrayQueueOf[T any](capacity int32) *MPSCArrayQueueOf[T] {
+	size := roundUpPowerOf2(capacity)
+
+	q := &MPSCArrayQueueOf[T]{
+		mask:   uint64(size - 1),
+		buffer: make([]arraySlot[T], size),
+	}
+	for i := range q.buffer {
+		q.buffer[i].seq = uint64(i)
+	}
+	return q
+}
+
+// Offer inserts the specified element at the tail of this queue. Returns false if this queue is full.
+func (q *MPSCArrayQueueOf[T]) Offer(v T) bool {
+	var slot *arraySlot[T]
+
+	pos := atomic.LoadUint64(&q.enqueuePos)
+	for {
+		slot = &q.buffer[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		if dif := int64(seq) - int64(pos); dif == 0 {
+			if atomic.CompareAndSwapUint64(&q.enqueuePos, pos, pos+1) {
+				break
+			}
+		} else if dif < 0 {
+			// slot is still occupied by previous lap
+			return false
+		}
+		pos = atomic.LoadUint64(&q.enqueuePos)
+	}
+
+	slot.v = v
+	atomic.StoreUint64(&slot.seq, pos+1)
+	return true
+}
+
+// head returns the head slot and its position, or nil slot if this queue is empty. If a producer has claimed the head slot
+// but not yet published its element, head waits for it.
+func (q *MPSCArrayQueueOf[T]) head() (slot *arraySlot[T], pos uint64) {
+	pos = atomic.LoadUint64(&q.dequeuePos)
+	slot = &q.buffer[pos&q.mask]
+	for atomic.LoadUint64(&slot.seq) != pos+1 {
+		if atomic.LoadUint64(&q.enqueuePos) == pos {
+			return nil, pos
+		}
+		runtime.Gosched()
+	}
+	return
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) Poll() (v T, ok bool) {
+	slot, pos := q.head()
+	if slot == nil {
+		return
+	}
+
+	var zero T
+	v, slot.v, ok = slot.v, zero, true
+	atomic.StoreUint64(&slot.seq, pos+q.mask+1)
+	atomic.StoreUint64(&q.dequeuePos, pos+1)
+	return
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) Peek() (v T, ok bool) {
+	if slot, _ := q.head(); slot != nil {
+		v, ok = slot.v, true
+	}
+	return
+}
+
+// Size returns the number of elements in this queue. The returned value could be inaccurate
+// because of concurrent updates.
+func (q *MPSCArrayQueueOf[T]) Size() int32 {
+	return boundedSize(&q.enqueuePos, &q.dequeuePos, q.mask+1)
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *MPSCArrayQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Capacity returns the capacity of this queue.
+func (q *MPSCArrayQueueOf[T]) Capacity() int32 {
+	return int32(q.mask + 1)
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed. Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	return consumerRemoveFunc(q.elements, q.Poll, match)
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+// Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) ContainsFunc(match func(T) bool) bool {
+	return consumerContainsFunc(q.elements, match)
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+// Elements offered concurrently may not be included. Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) ToSlice() []T {
+	return consumerToSlice(q.elements)
+}
+
+// elements yields the storage of each element from head to tail. If a producer has claimed a slot
+// but not yet published its element, elements waits for it.
+func (q *MPSCArrayQueueOf[T]) elements(yield func(*T) bool) {
+	end := atomic.LoadUint64(&q.enqueuePos)
+	for pos := atomic.LoadUint64(&q.dequeuePos); pos < end; pos++ {
+		slot := &q.buffer[pos&q.mask]
+		for atomic.LoadUint64(&slot.seq) != pos+1 {
+			runtime.Gosched()
+		}
+		if !yield(&slot.v) {
+			return
+		}
+	}
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken by ToSlice. Remove of the iterator does nothing. Must be called from the single consumer routine.
+func (q *MPSCArrayQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(q.ToSlice())
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"testing"
+)
+
+func TestMPSCArrayQueue_Producer(t *testing.T) {
+	testProducer(t, NewBoundedQueue(MPSCArrayQueueType, maxNumberProducer*numberEle))
+}
+
+func TestMPSCArrayQueue_Collection(t *testing.T) {
+	testCollection(t, NewQueue(MPSCArrayQueueType))
+}
+
+func TestMPSCArrayQueueOf_RemoveFunc(t *testing.T) {
+	testRemoveSingleConsumerOf(t, NewMPSCArrayQueueOf[*ele](64), maxNumberProducer)
+}
+
+func TestMPSCArrayQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewBoundedQueueOf[int](MPSCArrayQueueType, numberEle))
+}
+
+func TestMPSCArrayQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewBoundedQueueOf[int](MPSCArrayQueueType, numberEle), true)
+}
+
+func TestMPSCArrayQueueOf_SingleConsumer(t *testing.T) {
+	testSingleConsumerOf(t, NewBoundedQueueOf[*ele](MPSCArrayQueueType, 64), maxNumberProducer)
+}
+
+func TestMPSCArrayQueueOf_Capacity(t *testing.T) {
+	q := NewMPSCArrayQueueOf[int](3)
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 4; i++ {
+			if !q.Offer(i) {
+				t.Fatal(lap, i)
+			}
+		}
+
+		if q.Offer(4) || q.Size() != 4 || q.Capacity() != 4 {
+			t.Fatal(q.Size())
+		}
+
+		for i := 0; i < 4; i++ {
+			if v, ok := q.Poll(); !ok || v != i {
+				t.Fatal(v, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// MPSCLinkedQueueOf is a lock-free, unbounded, multi-producer single-consumer linked-list queue
+// based on Dmitry Vyukov's intrusive MPSC node-based queue, similar to JCTools MpscLinkedQueue.
+//
+// Producers swap the tail with their new node then link the previous tail to it,
+// so Offer is wait-free and never retries.
+//
+// Poll and Peek must be called from a single routine. Other methods are safe for concurrent use.
+type MPSCLinkedQueueOf[T any] struct {
+	// The padding members below are here to ensure head and tail are on separate cache lines.
+	// This prevents false sharing and hence improves performance.
+	_ internal.CacheLinePad
+	h unsafe.Pointer // head, owned by consumer
+	_ internal.CacheLinePad
+	t unsafe.Pointer // tail
+	_ internal.CacheLinePad
+}
+
+// NewMPSCLinkedQueueOf creates new MPSCLinkedQueueOf.
+func NewMPSCLinkedQueueOf[T any]() *MPSCLinkedQueueOf[T] {
+	q := &MPSCLinkedQueueOf[T]{
+		t: unsafe.Pointer(&linkedListNode[T]{}),
+	}
+	q.h = q.t
+	return q
+}
+
+// Offer inserts the specified element at the tail of this queue.
+// As the queue is unbounded, this method will never return false.
+func (q *MPSCLinkedQueueOf[T]) Offer(v T) bool {
+	newNode := unsafe.Pointer(newLinkedListNode(v))
+	prev := atomic.SwapPointer(&q.t, newNode)
+	(*linkedListNode[T])(prev).setNext(newNode)
+	return true
+}
+
+// first returns the node following stub, or nil if this queue is empty. If a producer has swapped
+// the tail but not yet linked its node, first waits for it.
+func (q *MPSCLinkedQueueOf[T]) first() unsafe.Pointer {
+	h := atomic.LoadPointer(&q.h)
+	_h := (*linkedListNode[T])(h)
+
+	next := _h.next()
+	for next == nil && atomic.LoadPointer(&q.t) != h {
+		runtime.Gosched()
+		next = _h.next()
+	}
+	return next
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) Poll() (v T, ok bool) {
+	next := q.first()
+	if next == nil {
+		return
+	}
+
+	// next becomes the new stub node
+	_next := (*linkedListNode[T])(next)
+
+	var zero T
+	v, _next._v, ok = _next._v, zero, true
+	atomic.StorePointer(&q.h, next)
+	return
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) Peek() (v T, ok bool) {
+	if next := q.first(); next != nil {
+		v, ok = (*linkedListNode[T])(next).value(), true
+	}
+	return
+}
+
+// Size returns the number of elements in this queue. If this queue
+// contains more than math.MaxInt32 elements, returns math.MaxInt32.
+// Like JDKLinkedQueueOf, this method is NOT a constant-time operation.
+func (q *MPSCLinkedQueueOf[T]) Size() int32 {
+	return linkedSize[T](atomic.LoadPointer(&q.h))
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *MPSCLinkedQueueOf[T]) IsEmpty() bool {
+	return atomic.LoadPointer(&q.h) == atomic.LoadPointer(&q.t)
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed. Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	return consumerRemoveFunc(q.elements, q.Poll, match)
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+// Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) ContainsFunc(match func(T) bool) bool {
+	return consumerContainsFunc(q.elements, match)
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+// Elements offered concurrently may not be included. Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) ToSlice() []T {
+	return consumerToSlice(q.elements)
+}
+
+func (q *MPSCLinkedQueueOf[T]) elements(yield func(*T) bool) {
+	linkedElements(atomic.LoadPointer(&q.h), yield)
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken by ToSlice. Remove of the iterator does nothing. Must be called from the single consumer routine.
+func (q *MPSCLinkedQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(q.ToSlice())
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"testing"
+)
+
+func TestMPSCLinkedQueue_Producer(t *testing.T) {
+	testProducer(t, NewBoundedQueue(MPSCLinkedQueueType, maxNumberProducer*numberEle))
+}
+
+func TestMPSCLinkedQueue_Collection(t *testing.T) {
+	testCollection(t, NewQueue(MPSCLinkedQueueType))
+}
+
+func TestMPSCLinkedQueueOf_RemoveFunc(t *testing.T) {
+	testRemoveSingleConsumerOf(t, NewMPSCLinkedQueueOf[*ele](), maxNumberProducer)
+}
+
+func TestMPSCLinkedQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewBoundedQueueOf[int](MPSCLinkedQueueType, numberEle))
+}
+
+func TestMPSCLinkedQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewBoundedQueueOf[int](MPSCLinkedQueueType, numberEle), true)
+}
+
+func TestMPSCLinkedQueueOf_SingleConsumer(t *testing.T) {
+	testSingleConsumerOf(t, NewBoundedQueueOf[*ele](MPSCLinkedQueueType, 0), maxNumberProducer)
+}
@@ -16,48 +16,55 @@ package queue
 
 import (
 	"container/list"
+	"iter"
 	"sync"
 )
 
-// MutexLinkedQueue mutex-based concurrent linked list queue.
-type MutexLinkedQueue struct {
+// MutexLinkedQueueOf mutex-based concurrent linked list queue of T.
+type MutexLinkedQueueOf[T any] struct {
 	l     *list.List
 	mutex sync.RWMutex
 }
 
-// NewMutexLinkedQueue creates new MutexLinkedQueue.
-func NewMutexLinkedQueue() *MutexLinkedQueue {
-	return &MutexLinkedQueue{
+// NewMutexLinkedQueueOf creates new MutexLinkedQueueOf.
+func NewMutexLinkedQueueOf[T any]() *MutexLinkedQueueOf[T] {
+	return &MutexLinkedQueueOf[T]{
 		l: list.New(),
 	}
 }
 
-// Offer inserts the specified element into this queue if it is possible to do so immediately
-// without violating capacity restrictions. Return nil if success.
-func (queue *MutexLinkedQueue) Offer(v interface{}) {
-	if v != nil {
-		queue.mutex.Lock()
-		queue.l.PushBack(v)
-		queue.mutex.Unlock()
-	}
+// valueOf returns the element stored in e. Nil interface elements are stored as untyped nil,
+// thus the assertion must not panic on them.
+func valueOf[T any](e *list.Element) T {
+	v, _ := e.Value.(T)
+	return v
 }
 
-// Poll retrieve and removes the head of this queue, or returns nil if this queue is empty.
-func (queue *MutexLinkedQueue) Poll() (v interface{}) {
+// Offer inserts the specified element into this queue.
+// As the queue is unbounded, this method will never return false.
+func (queue *MutexLinkedQueueOf[T]) Offer(v T) bool {
+	queue.mutex.Lock()
+	queue.l.PushBack(v)
+	queue.mutex.Unlock()
+	return true
+}
+
+// Poll retrieve and removes the head of this queue. Returns false if this queue is empty.
+func (queue *MutexLinkedQueueOf[T]) Poll() (v T, ok bool) {
 	queue.mutex.Lock()
 	if e := queue.l.Front(); e != nil {
-		v = e.Value
+		v, ok = valueOf[T](e), true
 		queue.l.Remove(e)
 	}
 	queue.mutex.Unlock()
 	return
 }
 
-// Peek retrieve, but does not remove, the head of this queue, or returns nil if this queue is empty.
-func (queue *MutexLinkedQueue) Peek() (v interface{}) {
+// Peek retrieve, but does not remove, the head of this queue. Returns false if this queue is empty.
+func (queue *MutexLinkedQueueOf[T]) Peek() (v T, ok bool) {
 	queue.mutex.RLock()
 	if e := queue.l.Front(); e != nil {
-		v = e.Value
+		v, ok = valueOf[T](e), true
 	}
 	queue.mutex.RUnlock()
 	return
@@ -65,7 +72,7 @@ func (queue *MutexLinkedQueue) Peek() (v interface{}) {
 
 // Size returns the number of elements in this queue. If this queue
 // contains more than math.MaxInt32 elements, returns math.MaxInt32.
-func (queue *MutexLinkedQueue) Size() (size int32) {
+func (queue *MutexLinkedQueueOf[T]) Size() (size int32) {
 	queue.mutex.RLock()
 	size = int32(queue.l.Len())
 	queue.mutex.RUnlock()
@@ -73,10 +80,200 @@ func (queue *MutexLinkedQueue) Size() (size int32) {
 }
 
 // IsEmpty returns if this queue contains no elements
-func (queue *MutexLinkedQueue) IsEmpty() (empt bool) {
+func (queue *MutexLinkedQueueOf[T]) IsEmpty() (empt bool) {
 	return queue.Size() == 0
 }
 
+// AddAll appends all of the given elements to the tail of this queue, in order, atomically.
+// Returns false if values is empty.
+func (queue *MutexLinkedQueueOf[T]) AddAll(values ...T) bool {
+	queue.mutex.Lock()
+	for _, v := range values {
+		queue.l.PushBack(v)
+	}
+	queue.mutex.Unlock()
+	return len(values) > 0
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed.
+func (queue *MutexLinkedQueueOf[T]) RemoveFunc(match func(T) bool) (removed bool) {
+	queue.mutex.Lock()
+	for e := queue.l.Front(); e != nil; e = e.Next() {
+		if match(valueOf[T](e)) {
+			queue.l.Remove(e)
+			removed = true
+			break
+		}
+	}
+	queue.mutex.Unlock()
+	return
+}
+
+// RemoveIf removes all elements of this queue which satisfy filter.
+// Returns true if any element was removed.
+func (queue *MutexLinkedQueueOf[T]) RemoveIf(filter func(T) bool) (removed bool) {
+	queue.mutex.Lock()
+	for e := queue.l.Front(); e != nil; {
+		next := e.Next()
+		if filter(valueOf[T](e)) {
+			queue.l.Remove(e)
+			removed = true
+		}
+		e = next
+	}
+	queue.mutex.Unlock()
+	return
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+func (queue *MutexLinkedQueueOf[T]) ContainsFunc(match func(T) bool) (found bool) {
+	queue.mutex.RLock()
+	for e := queue.l.Front(); e != nil && !found; e = e.Next() {
+		found = match(valueOf[T](e))
+	}
+	queue.mutex.RUnlock()
+	return
+}
+
+// Clear removes all of the elements from this queue.
+func (queue *MutexLinkedQueueOf[T]) Clear() {
+	queue.mutex.Lock()
+	queue.l.Init()
+	queue.mutex.Unlock()
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+func (queue *MutexLinkedQueueOf[T]) ToSlice() (values []T) {
+	queue.mutex.RLock()
+	if n := queue.l.Len(); n > 0 {
+		values = make([]T, 0, n)
+		for e := queue.l.Front(); e != nil; e = e.Next() {
+			values = append(values, valueOf[T](e))
+		}
+	}
+	queue.mutex.RUnlock()
+	return
+}
+
+// All returns an iterator over the elements in this queue, in proper sequence, without removing them.
+//
+// The iteration is weakly consistent: it yields a snapshot of the elements taken when the iteration starts,
+// thus elements offered or polled afterwards are not reflected. The lock is not held while yielding,
+// so it is safe to operate on this queue from the loop body.
+func (queue *MutexLinkedQueueOf[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range queue.ToSlice() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator which polls and yields the elements of this queue until it is empty.
+// Each yielded element has been removed from this queue. Elements offered concurrently are yielded
+// as long as the queue does not become empty. Stopping the iteration early leaves the remaining elements in this queue.
+func (queue *MutexLinkedQueueOf[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v, ok := queue.Poll(); ok && yield(v); v, ok = queue.Poll() {
+		}
+	}
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken by ToSlice. Remove of the iterator does nothing, use RemoveFunc instead.
+func (queue *MutexLinkedQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(queue.ToSlice())
+}
+
+// MutexLinkedQueue mutex-based concurrent linked list queue.
+// It is a thin wrapper over MutexLinkedQueueOf[interface{}], where nil indicates empty queue.
+type MutexLinkedQueue struct {
+	q *MutexLinkedQueueOf[interface{}]
+}
+
+// NewMutexLinkedQueue creates new MutexLinkedQueue.
+func NewMutexLinkedQueue() *MutexLinkedQueue {
+	return &MutexLinkedQueue{
+		q: NewMutexLinkedQueueOf[interface{}](),
+	}
+}
+
+// Offer inserts the specified element into this queue if it is possible to do so immediately
+// without violating capacity restrictions. Nil element is ignored.
+func (queue *MutexLinkedQueue) Offer(v interface{}) {
+	if v != nil {
+		queue.q.Offer(v)
+	}
+}
+
+// Poll retrieve and removes the head of this queue, or returns nil if this queue is empty.
+func (queue *MutexLinkedQueue) Poll() interface{} {
+	v, _ := queue.q.Poll()
+	return v
+}
+
+// Peek retrieve, but does not remove, the head of this queue, or returns nil if this queue is empty.
+func (queue *MutexLinkedQueue) Peek() interface{} {
+	v, _ := queue.q.Peek()
+	return v
+}
+
+// Size returns the number of elements in this queue. If this queue
+// contains more than math.MaxInt32 elements, returns math.MaxInt32.
+func (queue *MutexLinkedQueue) Size() int32 {
+	return queue.q.Size()
+}
+
+// IsEmpty returns if this queue contains no elements
+func (queue *MutexLinkedQueue) IsEmpty() bool {
+	return queue.q.IsEmpty()
+}
+
+// AddAll appends all of the given elements to the tail of this queue, in order, atomically.
+// Returns false without inserting anything if values is empty or any element is nil.
+func (queue *MutexLinkedQueue) AddAll(values ...interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return false
+		}
+	}
+	return queue.q.AddAll(values...)
+}
+
+// Remove removes a single instance of the specified element from this queue, if present.
+// Returns true if an element was removed.
+func (queue *MutexLinkedQueue) Remove(v interface{}) bool {
+	return v != nil && queue.q.RemoveFunc(func(item interface{}) bool { return item == v })
+}
+
+// Contains returns true if this queue contains the specified element.
+func (queue *MutexLinkedQueue) Contains(v interface{}) bool {
+	return v != nil && queue.q.ContainsFunc(func(item interface{}) bool { return item == v })
+}
+
+// Clear removes all of the elements from this queue.
+func (queue *MutexLinkedQueue) Clear() {
+	queue.q.Clear()
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+func (queue *MutexLinkedQueue) ToSlice() []interface{} {
+	return queue.q.ToSlice()
+}
+
+// All returns an iterator over the elements in this queue without removing them. See MutexLinkedQueueOf.All.
+func (queue *MutexLinkedQueue) All() iter.Seq[interface{}] {
+	return queue.q.All()
+}
+
+// Drain returns an iterator which polls and yields the elements of this queue until it is empty.
+// See MutexLinkedQueueOf.Drain.
+func (queue *MutexLinkedQueue) Drain() iter.Seq[interface{}] {
+	return queue.q.Drain()
+}
+
 // Iterator not supported. MutexLinkedQueue not support iterator.
 func (queue *MutexLinkedQueue) Iterator() Iterator {
 	return nil
@@ -15,6 +15,7 @@
 package queue
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -25,3 +26,66 @@ func TestMutexLinkedQueue_Producer(t *testing.T) {
 func TestMutexLinkedQueue_Mix(t *testing.T) {
 	testMix(t, NewQueue(MutexLinkedQueueType), 50, 50)
 }
+
+func TestMutexLinkedQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewQueueOf[int](MutexLinkedQueueType))
+}
+
+func TestMutexLinkedQueueOf_NilInterface(t *testing.T) {
+	q := NewMutexLinkedQueueOf[error]()
+	q.AddAll(nil, errors.New("x"), nil)
+
+	if v, ok := q.Peek(); !ok || v != nil {
+		t.Fatal(v, ok)
+	}
+	if !q.ContainsFunc(func(err error) bool { return err != nil }) || len(q.ToSlice()) != 3 {
+		t.Fatal()
+	}
+	if !q.RemoveIf(func(err error) bool { return err == nil }) || q.Size() != 1 {
+		t.Fatal()
+	}
+	if v, ok := q.Poll(); !ok || v == nil {
+		t.Fatal(v, ok)
+	}
+}
+
+func TestMutexLinkedQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewMutexLinkedQueueOf[int](), true)
+}
+
+func TestMutexLinkedQueue_Collection(t *testing.T) {
+	testCollection(t, NewQueue(MutexLinkedQueueType))
+}
+
+func TestMutexLinkedQueueOf_RemoveIf(t *testing.T) {
+	q := NewMutexLinkedQueueOf[int]()
+	q.AddAll(0, 1, 2, 3, 4, 5)
+
+	if !q.RemoveIf(func(v int) bool { return v%2 == 1 }) || q.RemoveIf(func(v int) bool { return v > 5 }) {
+		t.Fatal()
+	}
+
+	if s := q.ToSlice(); len(s) != 3 || s[0] != 0 || s[1] != 2 || s[2] != 4 {
+		t.Fatal(s)
+	}
+}
+
+func TestMutexLinkedQueueOf_All(t *testing.T) {
+	testWeaklyConsistentOf(t, NewMutexLinkedQueueOf[int]())
+
+	// elements offered from the loop body are not part of the snapshot
+	q := NewMutexLinkedQueue()
+	q.AddAll(1, 2, 3)
+	count := 0
+	for v := range q.All() {
+		q.Offer(v)
+		count++
+	}
+	if count != 3 || q.Size() != 6 {
+		t.Fatal(count, q.Size())
+	}
+}
+
+func TestMutexLinkedQueueOf_Drain(t *testing.T) {
+	testDrainOf(t, NewMutexLinkedQueueOf[int]())
+}
@@ -19,44 +19,85 @@ import (
 	"unsafe"
 )
 
-type linkedListNode struct {
-	_v interface{}    // real value
+type linkedListNode[T any] struct {
+	_v T              // real value
 	_i unsafe.Pointer // wrapper over value
 	_n unsafe.Pointer // next
 }
 
-func newLinkedListNode(v interface{}) *linkedListNode {
-	return &linkedListNode{
+func newLinkedListNode[T any](v T) *linkedListNode[T] {
+	n := &linkedListNode[T]{
 		_v: v,
-		_i: unsafe.Pointer(&v),
 		_n: nil,
 	}
+	n._i = unsafe.Pointer(&n._v)
+	return n
 }
 
-func (n *linkedListNode) value() interface{} {
+func (n *linkedListNode[T]) value() T {
 	return n._v
 }
 
-func (n *linkedListNode) next() unsafe.Pointer {
+func (n *linkedListNode[T]) next() unsafe.Pointer {
 	return atomic.LoadPointer(&n._n)
 }
 
-func (n *linkedListNode) item() unsafe.Pointer {
+func (n *linkedListNode[T]) item() unsafe.Pointer {
 	return atomic.LoadPointer(&n._i)
 }
 
-func (n *linkedListNode) setItemNil() {
+func (n *linkedListNode[T]) setItemNil() {
 	atomic.StorePointer(&n._i, nil)
 }
 
-func (n *linkedListNode) casItemNil(old unsafe.Pointer) bool {
+func (n *linkedListNode[T]) casItemNil(old unsafe.Pointer) bool {
 	return atomic.CompareAndSwapPointer(&n._i, old, nil)
 }
 
-func (n *linkedListNode) casNext(old, new unsafe.Pointer) bool {
+func (n *linkedListNode[T]) casNext(old, new unsafe.Pointer) bool {
 	return atomic.CompareAndSwapPointer(&n._n, old, new)
 }
 
-func (n *linkedListNode) setNext(new unsafe.Pointer) {
+func (n *linkedListNode[T]) setNext(new unsafe.Pointer) {
 	atomic.StorePointer(&n._n, new)
 }
+
+type dequeNode[T any] struct {
+	linkedListNode[T]
+	_p unsafe.Pointer // prev
+}
+
+func newDequeNode[T any](v T) *dequeNode[T] {
+	n := &dequeNode[T]{}
+	n._v = v
+	n._i = unsafe.Pointer(&n._v)
+	return n
+}
+
+func (n *dequeNode[T]) hasItem() bool {
+	return n.item() != nil
+}
+
+func (n *dequeNode[T]) nextNode() *dequeNode[T] {
+	return (*dequeNode[T])(n.next())
+}
+
+func (n *dequeNode[T]) prevNode() *dequeNode[T] {
+	return (*dequeNode[T])(atomic.LoadPointer(&n._p))
+}
+
+func (n *dequeNode[T]) casNextNode(old, new *dequeNode[T]) bool {
+	return n.casNext(unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+func (n *dequeNode[T]) casPrevNode(old, new *dequeNode[T]) bool {
+	return atomic.CompareAndSwapPointer(&n._p, unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+func (n *dequeNode[T]) setNextNode(new *dequeNode[T]) {
+	n.setNext(unsafe.Pointer(new))
+}
+
+func (n *dequeNode[T]) setPrevNode(new *dequeNode[T]) {
+	atomic.StorePointer(&n._p, unsafe.Pointer(new))
+}
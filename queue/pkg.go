@@ -14,6 +14,11 @@
 
 package queue
 
+import (
+	"context"
+	"time"
+)
+
 // Type is the type of queue.
 type Type byte
 
@@ -22,12 +27,27 @@ const (
 	JDKLinkedQueueType Type = iota
 	// MutexLinkedQueueType indicates MutexLinkedQueue.
 	MutexLinkedQueueType
+	// MPMCArrayQueueType indicates MPMCArrayQueue.
+	MPMCArrayQueueType
+	// SPSCArrayQueueType indicates SPSCArrayQueueOf. Offer must be called from a single routine,
+	// Poll and Peek must be called from a single routine.
+	SPSCArrayQueueType
+	// SPSCLinkedQueueType indicates SPSCLinkedQueueOf. Offer must be called from a single routine,
+	// Poll and Peek must be called from a single routine.
+	SPSCLinkedQueueType
+	// MPSCArrayQueueType indicates MPSCArrayQueueOf. Poll and Peek must be called from a single routine.
+	MPSCArrayQueueType
+	// MPSCLinkedQueueType indicates MPSCLinkedQueueOf. Poll and Peek must be called from a single routine.
+	MPSCLinkedQueueType
 )
 
+// DefaultCapacity is the capacity of bounded queues created by NewQueue and NewQueueOf.
+const DefaultCapacity = 1024
+
 // Queue interface.
 type Queue interface {
 	// Offer inserts the specified element into this queue if it is possible to do so immediately
-	// without violating capacity restrictions. Return nil if success.
+	// without violating capacity restrictions. Nil element is ignored.
 	Offer(v interface{})
 	// Poll retrieve and removes the head of this queue, or returns nil if this queue is empty.
 	Poll() interface{}
@@ -39,6 +59,31 @@ type Queue interface {
 	IsEmpty() bool
 	// Iterator returns an iterator over the elements in this collection.
 	Iterator() Iterator
+	// AddAll inserts all of the given elements into this queue, in order.
+	// Returns false if values is empty, any element is nil or not all elements were inserted.
+	AddAll(values ...interface{}) bool
+	// Remove removes a single instance of the specified element from this queue, if present.
+	// Returns true if an element was removed. Elements are compared with ==, thus it panics if v and an element
+	// have the same uncomparable dynamic type, such as slice or map. Use RemoveFunc of generic queues for those.
+	Remove(v interface{}) bool
+	// Contains returns true if this queue contains the specified element.
+	// Elements are compared with ==, the same as Remove.
+	Contains(v interface{}) bool
+	// Clear removes all of the elements from this queue.
+	Clear()
+	// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+	ToSlice() []interface{}
+}
+
+// BoundedQueue is a Queue with fixed capacity. Offer silently drops the element when there is
+// no space currently available, use TryOffer to find out whether the element was inserted.
+type BoundedQueue interface {
+	Queue
+	// TryOffer inserts the specified element into this queue if it is possible to do so immediately
+	// without violating capacity restrictions. Returns false if element is nil or there is no space currently available.
+	TryOffer(v interface{}) bool
+	// Capacity returns the capacity of this queue.
+	Capacity() int32
 }
 
 // Iterator interface.
@@ -52,11 +97,97 @@ type Iterator interface {
 	Remove()
 }
 
-// NewQueue create new queue based on type
+// QueueOf is the generic version of Queue. Unlike Queue, zero values of T are valid elements,
+// thus Poll and Peek report whether an element is present.
+type QueueOf[T any] interface {
+	// Offer inserts the specified element into this queue if it is possible to do so immediately
+	// without violating capacity restrictions. Returns false if there is no space currently available.
+	Offer(v T) bool
+	// Poll retrieve and removes the head of this queue. Returns false if this queue is empty.
+	Poll() (T, bool)
+	// Peek retrieve, but does not remove, the head of this queue. Returns false if this queue is empty.
+	Peek() (T, bool)
+	// Size returns the size of current queue.
+	Size() int32
+	// IsEmpty returns if this queue contains no elements.
+	IsEmpty() bool
+	// Iterator returns an iterator over the elements in this collection, which is never nil.
+	// Queues which could not iterate in place return an iterator over a snapshot, whose Remove does nothing.
+	Iterator() IteratorOf[T]
+}
+
+// BlockingQueueOf is a QueueOf that additionally supports operations that wait for the queue
+// to become non-empty when retrieving an element, and wait for space to become available
+// when storing an element.
+type BlockingQueueOf[T any] interface {
+	QueueOf[T]
+	// Put inserts the specified element into this queue, waiting if necessary for space to become available.
+	// Returns ctx.Err() if ctx is done before the element could be inserted.
+	Put(ctx context.Context, v T) error
+	// Take retrieves and removes the head of this queue, waiting if necessary until an element becomes available.
+	// Returns ctx.Err() if ctx is done before an element is available.
+	Take(ctx context.Context) (T, error)
+	// OfferTimeout inserts the specified element into this queue, waiting up to the specified timeout
+	// if necessary for space to become available. Returns false if timeout elapses.
+	OfferTimeout(v T, timeout time.Duration) bool
+	// PollTimeout retrieves and removes the head of this queue, waiting up to the specified timeout
+	// if necessary for an element to become available. Returns false if timeout elapses.
+	PollTimeout(timeout time.Duration) (T, bool)
+	// RemainingCapacity returns the number of additional elements that this queue can ideally accept without blocking.
+	RemainingCapacity() int32
+	// DrainTo removes at most n available elements from this queue and returns them.
+	// If n is negative, all available elements are removed.
+	DrainTo(n int) []T
+}
+
+// DequeOf is a QueueOf that supports element insertion and removal at both ends.
+// Offer, Poll and Peek operate at the end, the front and the front of deque respectively.
+type DequeOf[T any] interface {
+	QueueOf[T]
+	// OfferFirst inserts the specified element at the front of this deque.
+	OfferFirst(v T) bool
+	// OfferLast inserts the specified element at the end of this deque.
+	OfferLast(v T) bool
+	// PollFirst retrieves and removes the first element of this deque. Returns false if this deque is empty.
+	PollFirst() (T, bool)
+	// PollLast retrieves and removes the last element of this deque. Returns false if this deque is empty.
+	PollLast() (T, bool)
+	// PeekFirst retrieves, but does not remove, the first element of this deque. Returns false if this deque is empty.
+	PeekFirst() (T, bool)
+	// PeekLast retrieves, but does not remove, the last element of this deque. Returns false if this deque is empty.
+	PeekLast() (T, bool)
+	// DescendingIterator returns an iterator over the elements in this deque in reverse sequential order.
+	DescendingIterator() IteratorOf[T]
+}
+
+// IteratorOf is the generic version of Iterator.
+type IteratorOf[T any] interface {
+	// HasNext returns true if the iteration has more elements.
+	HasNext() bool
+	// Next returns the next element in the iteration.
+	Next() T
+	// Remove from the underlying collection the last element returned
+	// by this iterator.
+	Remove()
+}
+
+// NewQueue create new queue based on type. Bounded queue is created with DefaultCapacity.
 func NewQueue(t Type) Queue {
+	return NewBoundedQueue(t, DefaultCapacity)
+}
+
+// NewBoundedQueue create new queue based on type. Capacity is ignored for unbounded queue types.
+// Queues of bounded types implement BoundedQueue.
+func NewBoundedQueue(t Type, capacity int32) Queue {
 	switch t {
 	case MutexLinkedQueueType:
 		return NewMutexLinkedQueue()
+	case MPMCArrayQueueType:
+		return NewMPMCArrayQueue(capacity)
+	case SPSCArrayQueueType, MPSCArrayQueueType:
+		return boundedQueueAdapter{newQueueAdapter(NewBoundedQueueOf[interface{}](t, capacity))}
+	case SPSCLinkedQueueType, MPSCLinkedQueueType:
+		return newQueueAdapter(NewBoundedQueueOf[interface{}](t, capacity))
 	default:
 		return NewJDKLinkedQueue()
 	}
@@ -66,3 +197,201 @@ func NewQueue(t Type) Queue {
 func DefaultQueue() Queue {
 	return NewJDKLinkedQueue()
 }
+
+// NewQueueOf create new generic queue based on type. Bounded queue is created with DefaultCapacity.
+func NewQueueOf[T any](t Type) QueueOf[T] {
+	return NewBoundedQueueOf[T](t, DefaultCapacity)
+}
+
+// NewBoundedQueueOf create new generic queue based on type. Capacity is ignored for unbounded queue types.
+func NewBoundedQueueOf[T any](t Type, capacity int32) QueueOf[T] {
+	switch t {
+	case MutexLinkedQueueType:
+		return NewMutexLinkedQueueOf[T]()
+	case MPMCArrayQueueType:
+		return NewMPMCArrayQueueOf[T](capacity)
+	case SPSCArrayQueueType:
+		return NewSPSCArrayQueueOf[T](capacity)
+	case SPSCLinkedQueueType:
+		return NewSPSCLinkedQueueOf[T]()
+	case MPSCArrayQueueType:
+		return NewMPSCArrayQueueOf[T](capacity)
+	case MPSCLinkedQueueType:
+		return NewMPSCLinkedQueueOf[T]()
+	default:
+		return NewJDKLinkedQueueOf[T]()
+	}
+}
+
+// DefaultQueueOf returns generic jdk concurrent, non blocking queue.
+func DefaultQueueOf[T any]() QueueOf[T] {
+	return NewJDKLinkedQueueOf[T]()
+}
+
+// collectionOf is a QueueOf which supports traversal and removal of arbitrary elements.
+type collectionOf[T any] interface {
+	QueueOf[T]
+	RemoveFunc(match func(T) bool) bool
+	ContainsFunc(match func(T) bool) bool
+	ToSlice() []T
+}
+
+// queueAdapter adapts QueueOf[interface{}] to Queue, where nil indicates empty queue.
+type queueAdapter struct {
+	q collectionOf[interface{}]
+}
+
+func newQueueAdapter(q QueueOf[interface{}]) queueAdapter {
+	return queueAdapter{q: q.(collectionOf[interface{}])}
+}
+
+func (a queueAdapter) Offer(v interface{}) {
+	a.TryOffer(v)
+}
+
+func (a queueAdapter) TryOffer(v interface{}) bool {
+	return v != nil && a.q.Offer(v)
+}
+
+func (a queueAdapter) Poll() interface{} {
+	v, _ := a.q.Poll()
+	return v
+}
+
+func (a queueAdapter) Peek() interface{} {
+	v, _ := a.q.Peek()
+	return v
+}
+
+func (a queueAdapter) Size() int32 {
+	return a.q.Size()
+}
+
+func (a queueAdapter) IsEmpty() bool {
+	return a.q.IsEmpty()
+}
+
+func (a queueAdapter) Iterator() Iterator {
+	return a.q.Iterator()
+}
+
+func (a queueAdapter) AddAll(values ...interface{}) bool {
+	return offerAll(a.TryOffer, values)
+}
+
+// Remove removes a single instance of the specified element. For single consumer queues,
+// it must be called from the consumer routine.
+func (a queueAdapter) Remove(v interface{}) bool {
+	return v != nil && a.q.RemoveFunc(func(item interface{}) bool { return item == v })
+}
+
+// Contains returns true if the queue contains the specified element. For single consumer queues,
+// it must be called from the consumer routine.
+func (a queueAdapter) Contains(v interface{}) bool {
+	return v != nil && a.q.ContainsFunc(func(item interface{}) bool { return item == v })
+}
+
+// Clear polls all of the elements. For single consumer queues, it must be called from the consumer routine.
+func (a queueAdapter) Clear() {
+	for _, ok := a.q.Poll(); ok; _, ok = a.q.Poll() {
+	}
+}
+
+// ToSlice returns a slice containing all of the elements. For single consumer queues,
+// it must be called from the consumer routine.
+func (a queueAdapter) ToSlice() []interface{} {
+	return a.q.ToSlice()
+}
+
+// boundedQueueAdapter adapts bounded QueueOf[interface{}] to BoundedQueue.
+type boundedQueueAdapter struct {
+	queueAdapter
+}
+
+func (a boundedQueueAdapter) Capacity() int32 {
+	return a.q.(interface{ Capacity() int32 }).Capacity()
+}
+
+// offerAll offers values in order, stopping at the first one which is rejected.
+// Nothing is offered if any value is nil.
+func offerAll(offer func(interface{}) bool, values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return false
+		}
+	}
+	for _, v := range values {
+		if !offer(v) {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+// consumerRemoveFunc removes the first element which satisfies match from a single consumer queue.
+// The elements preceding it are shifted by one towards the tail, then the head is polled, thus the order
+// of remaining elements is kept. Consumer owns all published elements, so nothing is shifted under producers.
+func consumerRemoveFunc[T any](elements func(yield func(*T) bool), poll func() (T, bool), match func(T) bool) bool {
+	var path []*T
+	found := false
+	elements(func(p *T) bool {
+		path = append(path, p)
+		found = match(*p)
+		return !found
+	})
+	if !found {
+		return false
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		*path[i] = *path[i-1]
+	}
+	poll()
+	return true
+}
+
+// consumerContainsFunc returns true if a single consumer queue contains an element which satisfies match.
+func consumerContainsFunc[T any](elements func(yield func(*T) bool), match func(T) bool) (found bool) {
+	elements(func(p *T) bool {
+		found = match(*p)
+		return !found
+	})
+	return
+}
+
+// consumerToSlice returns a slice containing all of the elements of a single consumer queue, in proper sequence.
+func consumerToSlice[T any](elements func(yield func(*T) bool)) (values []T) {
+	elements(func(p *T) bool {
+		values = append(values, *p)
+		return true
+	})
+	return
+}
+
+// snapshotIter is an IteratorOf over a snapshot of elements, for queues which could not iterate their elements
+// in place. It does not reflect updates made after the snapshot, and its Remove does nothing.
+type snapshotIter[T any] struct {
+	values []T
+	next   int
+}
+
+func newSnapshotIter[T any](values []T) *snapshotIter[T] {
+	return &snapshotIter[T]{values: values}
+}
+
+// HasNext returns true if the snapshot has more elements.
+func (i *snapshotIter[T]) HasNext() bool {
+	return i.next < len(i.values)
+}
+
+// Next returns the next element of the snapshot, or zero value if there is none.
+func (i *snapshotIter[T]) Next() (v T) {
+	if i.next < len(i.values) {
+		v = i.values[i.next]
+		i.next++
+	}
+	return
+}
+
+// Remove does nothing, as the snapshot is detached from the queue.
+func (i *snapshotIter[T]) Remove() {}
@@ -16,6 +16,7 @@ package queue
 
 import (
 	"context"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -66,6 +67,14 @@ func Benchmark_JDKLinkedQueue_50P50C(b *testing.B) {
 	}
 }
 
+func Benchmark_MPMCArrayQueue_50P50C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(MPMCArrayQueueType, 50*numberEle), 50, 50)
+	}
+}
+
 func Benchmark_MutexLinkedQueue_50P10C(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -82,6 +91,14 @@ func Benchmark_JDKLinkedQueue_50P10C(b *testing.B) {
 	}
 }
 
+func Benchmark_MPMCArrayQueue_50P10C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(MPMCArrayQueueType, 50*numberEle), 50, 10)
+	}
+}
+
 func Benchmark_MutexLinkedQueue_10P50C(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -98,12 +115,102 @@ func Benchmark_JDKLinkedQueue_10P50C(b *testing.B) {
 	}
 }
 
+func Benchmark_MPMCArrayQueue_10P50C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(MPMCArrayQueueType, 10*numberEle), 10, 50)
+	}
+}
+
+func Benchmark_JDKLinkedQueue_1P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(JDKLinkedQueueType), 1, 1)
+	}
+}
+
+func Benchmark_SPSCArrayQueue_1P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(SPSCArrayQueueType, numberEle), 1, 1)
+	}
+}
+
+func Benchmark_SPSCLinkedQueue_1P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(SPSCLinkedQueueType), 1, 1)
+	}
+}
+
+func Benchmark_JDKLinkedQueue_50P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(JDKLinkedQueueType), 50, 1)
+	}
+}
+
+func Benchmark_MPSCArrayQueue_50P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(MPSCArrayQueueType, 50*numberEle), 50, 1)
+	}
+}
+
+func Benchmark_MPSCLinkedQueue_50P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(MPSCLinkedQueueType), 50, 1)
+	}
+}
+
+func Benchmark_JDKLinkedQueue_10P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(JDKLinkedQueueType), 10, 1)
+	}
+}
+
+func Benchmark_MPSCArrayQueue_10P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewBoundedQueue(MPSCArrayQueueType, 10*numberEle), 10, 1)
+	}
+}
+
+func Benchmark_MPSCLinkedQueue_10P1C(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchQueueMix(NewQueue(MPSCLinkedQueueType), 10, 1)
+	}
+}
+
 func benchQueueMix(q Queue, numberProducer, numberConsumer int) {
+	offer := func(v interface{}) bool {
+		q.Offer(v)
+		return true
+	}
+	if bounded, ok := q.(BoundedQueue); ok {
+		offer = bounded.TryOffer
+	}
+
 	var done int32
 	for i := 0; i < numberProducer; i++ {
 		go func(i int) {
 			for j := 0; j < numberEle; j++ {
-				q.Offer(&ele{key: i, value: -j})
+				for e := (&ele{key: i, value: -j}); !offer(e); {
+					runtime.Gosched()
+				}
 			}
 			atomic.AddInt32(&done, 1)
 		}(i)
@@ -16,6 +16,8 @@ package queue
 
 import (
 	"context"
+	"iter"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -135,3 +137,251 @@ func testConsumer(ctx context.Context, wg *sync.WaitGroup, q Queue, ch chan *ele
 		}
 	}
 }
+
+func testZeroValueOf(t *testing.T, q QueueOf[int]) {
+	if _, ok := q.Poll(); ok {
+		t.Fatal()
+	}
+
+	if _, ok := q.Peek(); ok {
+		t.Fatal()
+	}
+
+	for i := 0; i < numberEle; i++ {
+		q.Offer(i)
+	}
+
+	if v, ok := q.Peek(); !ok || v != 0 || int(q.Size()) != numberEle {
+		t.Fatal(v, ok)
+	}
+
+	for i := 0; i < numberEle; i++ {
+		if v, ok := q.Poll(); !ok || v != i {
+			t.Fatal(v, ok)
+		}
+	}
+
+	if _, ok := q.Poll(); ok || !q.IsEmpty() {
+		t.Fatal()
+	}
+}
+
+// testSnapshotIteratorOf checks the snapshot iterator of q, which iterates in insertion order if ordered.
+func testSnapshotIteratorOf(t *testing.T, q QueueOf[int], ordered bool) {
+	if iter := q.Iterator(); iter == nil || iter.HasNext() {
+		t.Fatal(iter)
+	}
+
+	for i := 0; i < 10; i++ {
+		q.Offer(i)
+	}
+	iter := q.Iterator()
+	q.Offer(10) // not reflected
+
+	seen := make(map[int]bool)
+	for i := 0; iter.HasNext(); i++ {
+		v := iter.Next()
+		if (ordered && v != i) || v < 0 || v >= 10 || seen[v] {
+			t.Fatal(i, v)
+		}
+		seen[v] = true
+		iter.Remove() // does nothing
+	}
+	if len(seen) != 10 || iter.Next() != 0 || q.Size() != 11 {
+		t.Fatal(seen, q.Size())
+	}
+}
+
+func testSingleConsumerOf(t *testing.T, q QueueOf[*ele], numberProducer int) {
+	for i := 0; i < numberProducer; i++ {
+		go func(producer int) {
+			for j := 0; j < numberEle; {
+				if q.Offer(&ele{key: producer, value: j}) {
+					j++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(i)
+	}
+
+	// elements from each producer must be polled in order
+	next := make([]int, numberProducer)
+	for i := 0; i < numberProducer*numberEle; {
+		if _, ok := q.Peek(); !ok {
+			runtime.Gosched()
+			continue
+		}
+
+		e, ok := q.Poll()
+		if !ok || e.value != next[e.key] {
+			t.Fatal(e, ok)
+		}
+		next[e.key]++
+		i++
+	}
+
+	if _, ok := q.Poll(); ok || !q.IsEmpty() || q.Size() != 0 {
+		t.Fatal()
+	}
+}
+
+func testCollection(t *testing.T, q Queue) {
+	if q.AddAll() || q.AddAll(1, nil) || !q.IsEmpty() {
+		t.Fatal()
+	}
+
+	if !q.AddAll(1, 2, 3, 2, 4) || q.Size() != 5 {
+		t.Fatal(q.Size())
+	}
+
+	if !q.Contains(2) || q.Contains(5) || q.Contains(nil) {
+		t.Fatal()
+	}
+
+	// removes first occurrence only
+	if !q.Remove(2) || q.Remove(5) || q.Remove(nil) {
+		t.Fatal()
+	}
+
+	if s := q.ToSlice(); len(s) != 4 || s[0] != 1 || s[1] != 3 || s[2] != 2 || s[3] != 4 {
+		t.Fatal(s)
+	}
+
+	q.Clear()
+	if !q.IsEmpty() || q.Poll() != nil || len(q.ToSlice()) != 0 || q.Contains(1) {
+		t.Fatal()
+	}
+
+	// queue is still usable after clear
+	if q.Offer(5); q.Peek() != 5 {
+		t.Fatal()
+	}
+}
+
+// testRemoveSingleConsumerOf checks that removing elements from the consumer routine keeps the order of the others,
+// while producers offer concurrently.
+func testRemoveSingleConsumerOf(t *testing.T, q collectionOf[*ele], numberProducer int) {
+	// removal traverses the queue, thus fewer elements than other tests
+	const number = numberEle / 10
+
+	for i := 0; i < numberProducer; i++ {
+		go func(producer int) {
+			for j := 0; j < number; {
+				if q.Offer(&ele{key: producer, value: j}) {
+					j++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(i)
+	}
+
+	isRemoved := func(e *ele) bool { return e.value%3 == 1 }
+
+	last := make([]int, numberProducer)
+	for i := range last {
+		last[i] = -1
+	}
+	for polled, removed := 0, 0; polled+removed < numberProducer*number; {
+		if q.RemoveFunc(isRemoved) {
+			removed++
+		}
+		if q.ContainsFunc(isRemoved) && len(q.ToSlice()) == 0 {
+			t.Fatal()
+		}
+
+		e, ok := q.Poll()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+
+		// elements of a producer are polled in order, skipping removed ones only
+		for v := last[e.key] + 1; v < e.value; v++ {
+			if !isRemoved(&ele{value: v}) {
+				t.Fatal(e, v)
+			}
+		}
+		if e.value <= last[e.key] {
+			t.Fatal(e, last[e.key])
+		}
+		last[e.key] = e.value
+		polled++
+	}
+
+	if _, ok := q.Poll(); ok || !q.IsEmpty() {
+		t.Fatal()
+	}
+}
+
+type rangeQueueOf[T any] interface {
+	QueueOf[T]
+	RemoveFunc(match func(T) bool) bool
+	All() iter.Seq[T]
+	Drain() iter.Seq[T]
+}
+
+// testWeaklyConsistentOf checks that All yields every element present during the whole iteration exactly once
+// and in order, while odd elements are removed and new elements are offered concurrently.
+func testWeaklyConsistentOf(t *testing.T, q rangeQueueOf[int]) {
+	for i := 0; i < numberEle; i++ {
+		q.Offer(i)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < numberEle; i += 2 {
+			q.RemoveFunc(func(v int) bool { return v == i })
+			q.Offer(numberEle + i)
+		}
+	}()
+
+	prev, evens := -1, 0
+	for v := range q.All() {
+		if v <= prev {
+			t.Fatal(prev, v)
+		}
+		if v < numberEle && v%2 == 0 {
+			evens++
+		}
+		prev = v
+	}
+	wg.Wait()
+
+	if evens != numberEle/2 {
+		t.Fatal(evens)
+	}
+}
+
+func testDrainOf(t *testing.T, q rangeQueueOf[int]) {
+	for i := 0; i < 10; i++ {
+		q.Offer(i)
+	}
+
+	// stopping early leaves the remaining elements
+	for v := range q.Drain() {
+		if v == 4 {
+			break
+		}
+	}
+	if v, ok := q.Peek(); !ok || v != 5 || q.Size() != 5 {
+		t.Fatal(v, ok)
+	}
+
+	// elements offered from the loop body are drained too
+	expected := 5
+	for v := range q.Drain() {
+		if v != expected {
+			t.Fatal(v, expected)
+		}
+		if expected++; v < 7 {
+			q.Offer(v + 5)
+		}
+	}
+	if expected != 12 || !q.IsEmpty() {
+		t.Fatal(expected)
+	}
+}
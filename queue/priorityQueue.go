@@ -0,0 +1,88 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"sync"
+)
+
+// PriorityQueueOf is an unbounded, thread-safe priority queue based on a binary heap guarded by mutex,
+// similar to OpenJDK PriorityBlockingQueue.
+//
+// Elements are ordered by the supplied comparator: the head of this queue is the least element.
+// Ties are broken arbitrarily.
+type PriorityQueueOf[T any] struct {
+	heap  binaryHeap[T]
+	mutex sync.Mutex
+}
+
+// NewPriorityQueueOf creates new PriorityQueueOf ordered by compare, which returns a negative number
+// when a < b, zero when a == b and a positive number when a > b.
+func NewPriorityQueueOf[T any](compare func(a, b T) int) *PriorityQueueOf[T] {
+	return &PriorityQueueOf[T]{
+		heap: binaryHeap[T]{
+			less: func(a, b T) bool {
+				return compare(a, b) < 0
+			},
+		},
+	}
+}
+
+// Offer inserts the specified element into this queue.
+// As the queue is unbounded, this method will never return false.
+func (q *PriorityQueueOf[T]) Offer(v T) bool {
+	q.mutex.Lock()
+	q.heap.push(v)
+	q.mutex.Unlock()
+	return true
+}
+
+// Poll retrieves and removes the least element of this queue. Returns false if this queue is empty.
+func (q *PriorityQueueOf[T]) Poll() (v T, ok bool) {
+	q.mutex.Lock()
+	v, ok = q.heap.pop()
+	q.mutex.Unlock()
+	return
+}
+
+// Peek retrieves, but does not remove, the least element of this queue. Returns false if this queue is empty.
+func (q *PriorityQueueOf[T]) Peek() (v T, ok bool) {
+	q.mutex.Lock()
+	v, ok = q.heap.peek()
+	q.mutex.Unlock()
+	return
+}
+
+// Size returns the number of elements in this queue.
+func (q *PriorityQueueOf[T]) Size() (size int32) {
+	q.mutex.Lock()
+	size = int32(q.heap.len())
+	q.mutex.Unlock()
+	return
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *PriorityQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, taken under the lock,
+// in no particular order. Remove of the iterator does nothing.
+func (q *PriorityQueueOf[T]) Iterator() IteratorOf[T] {
+	q.mutex.Lock()
+	values := append([]T(nil), q.heap.items...)
+	q.mutex.Unlock()
+	return newSnapshotIter(values)
+}
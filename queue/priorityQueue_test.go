@@ -0,0 +1,78 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func compareInt(a, b int) int {
+	return a - b
+}
+
+func TestPriorityQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewPriorityQueueOf(compareInt))
+}
+
+func TestPriorityQueueOf_Order(t *testing.T) {
+	q := NewPriorityQueueOf(compareInt)
+	for _, i := range rand.Perm(numberEle) {
+		q.Offer(i)
+	}
+
+	for i := 0; i < numberEle; i++ {
+		if v, ok := q.Poll(); !ok || v != i {
+			t.Fatal(v, ok)
+		}
+	}
+
+	if _, ok := q.Poll(); ok || !q.IsEmpty() {
+		t.Fatal()
+	}
+}
+
+func TestPriorityQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewPriorityQueueOf(compareInt), false)
+}
+
+func TestPriorityQueueOf_Concurrent(t *testing.T) {
+	q := NewPriorityQueueOf(compareInt)
+
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < numberEle; i += 8 {
+				q.Offer(i)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	if int(q.Size()) != numberEle {
+		t.Fatal(q.Size())
+	}
+
+	prev := -1
+	for v, ok := q.Poll(); ok; v, ok = q.Poll() {
+		if v <= prev {
+			t.Fatal(prev, v)
+		}
+		prev = v
+	}
+}
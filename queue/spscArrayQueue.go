@@ -0,0 +1,143 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"sync/atomic"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// SPSCArrayQueueOf is a lock-free, bounded, single-producer single-consumer array queue,
+// similar to JCTools SpscArrayQueue.
+//
+// Producer and consumer each own a cursor and keep a cached copy of the other's cursor,
+// thus the shared cursor is only read when the cached one indicates full or empty queue.
+//
+// Offer must be called from a single routine, Poll and Peek must be called from a single routine.
+// Other methods are safe for concurrent use.
+type SPSCArrayQueueOf[T any] struct {
+	// The padding members below are here to ensure producer and consumer fields are on separate cache lines.
+	// This prevents false sharing and hence improves performance.
+	_             internal.CacheLinePad
+	producerIndex uint64
+	consumerCache uint64 // producer-local cache of consumerIndex
+	_             internal.CacheLinePad
+	consumerIndex uint64
+	producerCache uint64 // consumer-local cache of producerIndex
+	_             internal.CacheLinePad
+	mask          uint64
+	buffer        []T
+}
+
+// NewSPSCArrayQueueOf creates new SPSCArrayQueueOf. Capacity is rounded up to the next power of two,
+// and at least 2.
+func NewSPSCArrayQueueOf[T any](capacity int32) *SPSCArrayQueueOf[T] {
+	size := roundUpPowerOf2(capacity)
+	return &SPSCArrayQueueOf[T]{
+		mask:   uint64(size - 1),
+		buffer: make([]T, size),
+	}
+}
+
+// Offer inserts the specified element at the tail of this queue. Returns false if this queue is full.
+// Must be called from the single producer routine.
+func (q *SPSCArrayQueueOf[T]) Offer(v T) bool {
+	pos := atomic.LoadUint64(&q.producerIndex)
+	if pos-q.consumerCache > q.mask {
+		if q.consumerCache = atomic.LoadUint64(&q.consumerIndex); pos-q.consumerCache > q.mask {
+			return false
+		}
+	}
+
+	q.buffer[pos&q.mask] = v
+	atomic.StoreUint64(&q.producerIndex, pos+1)
+	return true
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) Poll() (v T, ok bool) {
+	pos := atomic.LoadUint64(&q.consumerIndex)
+	if pos >= q.producerCache {
+		if q.producerCache = atomic.LoadUint64(&q.producerIndex); pos >= q.producerCache {
+			return
+		}
+	}
+
+	var zero T
+	v, q.buffer[pos&q.mask], ok = q.buffer[pos&q.mask], zero, true
+	atomic.StoreUint64(&q.consumerIndex, pos+1)
+	return
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) Peek() (v T, ok bool) {
+	pos := atomic.LoadUint64(&q.consumerIndex)
+	if pos >= q.producerCache {
+		if q.producerCache = atomic.LoadUint64(&q.producerIndex); pos >= q.producerCache {
+			return
+		}
+	}
+	return q.buffer[pos&q.mask], true
+}
+
+// Size returns the number of elements in this queue. The returned value could be inaccurate
+// because of concurrent updates.
+func (q *SPSCArrayQueueOf[T]) Size() int32 {
+	return boundedSize(&q.producerIndex, &q.consumerIndex, q.mask+1)
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *SPSCArrayQueueOf[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Capacity returns the capacity of this queue.
+func (q *SPSCArrayQueueOf[T]) Capacity() int32 {
+	return int32(q.mask + 1)
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed. Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	return consumerRemoveFunc(q.elements, q.Poll, match)
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+// Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) ContainsFunc(match func(T) bool) bool {
+	return consumerContainsFunc(q.elements, match)
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+// Elements offered concurrently may not be included. Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) ToSlice() []T {
+	return consumerToSlice(q.elements)
+}
+
+// elements yields the storage of each element from head to tail.
+func (q *SPSCArrayQueueOf[T]) elements(yield func(*T) bool) {
+	end := atomic.LoadUint64(&q.producerIndex)
+	for pos := atomic.LoadUint64(&q.consumerIndex); pos < end && yield(&q.buffer[pos&q.mask]); pos++ {
+	}
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken by ToSlice. Remove of the iterator does nothing. Must be called from the single consumer routine.
+func (q *SPSCArrayQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(q.ToSlice())
+}
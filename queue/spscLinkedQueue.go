@@ -0,0 +1,139 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+
+	"go.linecorp.com/garr/internal"
+)
+
+// SPSCLinkedQueueOf is a lock-free, unbounded, single-producer single-consumer linked-list queue.
+//
+// As there is only one producer and one consumer, nodes are linked and unlinked without CAS.
+//
+// Offer must be called from a single routine, Poll and Peek must be called from a single routine.
+// Other methods are safe for concurrent use.
+type SPSCLinkedQueueOf[T any] struct {
+	// The padding members below are here to ensure head and tail are on separate cache lines.
+	// This prevents false sharing and hence improves performance.
+	_ internal.CacheLinePad
+	h unsafe.Pointer // head, owned by consumer
+	_ internal.CacheLinePad
+	t unsafe.Pointer // tail, owned by producer
+	_ internal.CacheLinePad
+}
+
+// NewSPSCLinkedQueueOf creates new SPSCLinkedQueueOf.
+func NewSPSCLinkedQueueOf[T any]() *SPSCLinkedQueueOf[T] {
+	q := &SPSCLinkedQueueOf[T]{
+		t: unsafe.Pointer(&linkedListNode[T]{}),
+	}
+	q.h = q.t
+	return q
+}
+
+// Offer inserts the specified element at the tail of this queue.
+// As the queue is unbounded, this method will never return false.
+// Must be called from the single producer routine.
+func (q *SPSCLinkedQueueOf[T]) Offer(v T) bool {
+	newNode := unsafe.Pointer(newLinkedListNode(v))
+	(*linkedListNode[T])(q.t).setNext(newNode)
+	q.t = newNode
+	return true
+}
+
+// Poll retrieves and removes the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) Poll() (v T, ok bool) {
+	next := (*linkedListNode[T])(q.h).next()
+	if next == nil {
+		return
+	}
+
+	// next becomes the new stub node
+	_next := (*linkedListNode[T])(next)
+
+	var zero T
+	v, _next._v, ok = _next._v, zero, true
+	atomic.StorePointer(&q.h, next)
+	return
+}
+
+// Peek retrieves, but does not remove, the head of this queue. Returns false if this queue is empty.
+// Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) Peek() (v T, ok bool) {
+	if next := (*linkedListNode[T])(q.h).next(); next != nil {
+		v, ok = (*linkedListNode[T])(next).value(), true
+	}
+	return
+}
+
+// Size returns the number of elements in this queue. If this queue
+// contains more than math.MaxInt32 elements, returns math.MaxInt32.
+// Like JDKLinkedQueueOf, this method is NOT a constant-time operation.
+func (q *SPSCLinkedQueueOf[T]) Size() int32 {
+	return linkedSize[T](atomic.LoadPointer(&q.h))
+}
+
+// IsEmpty returns if this queue contains no elements.
+func (q *SPSCLinkedQueueOf[T]) IsEmpty() bool {
+	return (*linkedListNode[T])(atomic.LoadPointer(&q.h)).next() == nil
+}
+
+// RemoveFunc removes the first element which satisfies match from this queue, if present.
+// Returns true if an element was removed. Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) RemoveFunc(match func(T) bool) bool {
+	return consumerRemoveFunc(q.elements, q.Poll, match)
+}
+
+// ContainsFunc returns true if this queue contains an element which satisfies match.
+// Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) ContainsFunc(match func(T) bool) bool {
+	return consumerContainsFunc(q.elements, match)
+}
+
+// ToSlice returns a slice containing all of the elements in this queue, in proper sequence.
+// Elements offered concurrently may not be included. Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) ToSlice() []T {
+	return consumerToSlice(q.elements)
+}
+
+func (q *SPSCLinkedQueueOf[T]) elements(yield func(*T) bool) {
+	linkedElements(atomic.LoadPointer(&q.h), yield)
+}
+
+// Iterator returns an iterator over a snapshot of the elements in this queue, in proper sequence,
+// taken by ToSlice. Remove of the iterator does nothing. Must be called from the single consumer routine.
+func (q *SPSCLinkedQueueOf[T]) Iterator() IteratorOf[T] {
+	return newSnapshotIter(q.ToSlice())
+}
+
+// linkedElements yields the storage of each element following the given stub node, until the first node
+// which is not linked yet.
+func linkedElements[T any](stub unsafe.Pointer, yield func(*T) bool) {
+	for p := (*linkedListNode[T])(stub).next(); p != nil && yield(&(*linkedListNode[T])(p)._v); p = (*linkedListNode[T])(p).next() {
+	}
+}
+
+// linkedSize counts nodes following the given stub node.
+func linkedSize[T any](stub unsafe.Pointer) (count int32) {
+	for p := (*linkedListNode[T])(stub).next(); p != nil && count < math.MaxInt32; p = (*linkedListNode[T])(p).next() {
+		count++
+	}
+	return
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"testing"
+)
+
+func TestSPSCLinkedQueue_Queue(t *testing.T) {
+	q := NewQueue(SPSCLinkedQueueType)
+	if _, bounded := q.(BoundedQueue); bounded {
+		t.Fatal()
+	}
+
+	q.Offer(nil)
+	q.Offer(1)
+	if q.Size() != 1 || q.Peek() != 1 || q.Poll() != 1 || q.Poll() != nil || !q.IsEmpty() {
+		t.Fatal()
+	}
+}
+
+func TestSPSCLinkedQueue_Collection(t *testing.T) {
+	testCollection(t, NewQueue(SPSCLinkedQueueType))
+}
+
+func TestSPSCLinkedQueueOf_RemoveFunc(t *testing.T) {
+	testRemoveSingleConsumerOf(t, NewSPSCLinkedQueueOf[*ele](), 1)
+}
+
+func TestSPSCLinkedQueueOf_ZeroValue(t *testing.T) {
+	testZeroValueOf(t, NewBoundedQueueOf[int](SPSCLinkedQueueType, numberEle))
+}
+
+func TestSPSCLinkedQueueOf_Iterator(t *testing.T) {
+	testSnapshotIteratorOf(t, NewBoundedQueueOf[int](SPSCLinkedQueueType, numberEle), true)
+}
+
+func TestSPSCLinkedQueueOf_SingleConsumer(t *testing.T) {
+	testSingleConsumerOf(t, NewBoundedQueueOf[*ele](SPSCLinkedQueueType, 0), 1)
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import "time"
+
+// Ticker interface
+type Ticker interface {
+	// Tick returns current time in nanoseconds.
+	Tick() int64
+}
+
+// Clock is a Ticker which could also notify when a duration elapses on its own time scale.
+// DelayQueueOf waits for expired elements through it, thus a manual Clock drives the queue deterministically.
+type Clock interface {
+	Ticker
+	// After returns a channel which is closed once d has elapsed according to Tick,
+	// and a function which releases resources if the caller stops waiting before that.
+	After(d time.Duration) (<-chan struct{}, func())
+}
+
+var startTick = time.Now()
+
+type systemTicker struct{}
+
+func (s *systemTicker) Tick() int64 {
+	return time.Since(startTick).Nanoseconds()
+}
+
+func (s *systemTicker) After(d time.Duration) (<-chan struct{}, func()) {
+	return realTimeAfter(d)
+}
+
+// SystemTicker default ticker, based on monotonic clock. It implements Clock.
+var SystemTicker Ticker = &systemTicker{}
+
+// tickerClock adapts a Ticker which does not implement Clock, assuming it advances in real time.
+type tickerClock struct {
+	Ticker
+}
+
+func (c tickerClock) After(d time.Duration) (<-chan struct{}, func()) {
+	return realTimeAfter(d)
+}
+
+func clockOf(ticker Ticker) Clock {
+	if clock, ok := ticker.(Clock); ok {
+		return clock
+	}
+	return tickerClock{Ticker: ticker}
+}
+
+func realTimeAfter(d time.Duration) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+	timer := time.AfterFunc(d, func() { close(ch) })
+	return ch, func() { timer.Stop() }
+}
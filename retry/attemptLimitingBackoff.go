@@ -16,12 +16,15 @@ package retry
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 )
 
 // AttemptLimitingBackoff is a backoff which limits the number of attempts up to the specified value.
 type AttemptLimitingBackoff struct {
-	delegate Backoff
-	limit    int
+	delegate  Backoff
+	durations DurationBackoff // delegate as DurationBackoff
+	limit     int
 }
 
 // NewAttemptLimitingBackoff creates new AttemptLimitingBackoff.
@@ -31,11 +34,27 @@ func NewAttemptLimitingBackoff(delegate Backoff, limit int) (b *AttemptLimitingB
 	} else if limit <= 0 {
 		err = fmt.Errorf("maxAttempts: %d (expected: > 0)", limit)
 	} else {
-		b = &AttemptLimitingBackoff{delegate: delegate, limit: limit}
+		b = &AttemptLimitingBackoff{delegate: delegate, durations: AsDurationBackoff(delegate), limit: limit}
 	}
 	return
 }
 
+// Fresh returns a new AttemptLimitingBackoff over a fresh instance of the delegate if it is a StatefulBackoff,
+// otherwise returns this backoff.
+func (f *AttemptLimitingBackoff) Fresh() Backoff {
+	if _, ok := f.delegate.(StatefulBackoff); !ok {
+		return f
+	}
+	delegate := Fresh(f.delegate)
+	return &AttemptLimitingBackoff{delegate: delegate, durations: AsDurationBackoff(delegate), limit: f.limit}
+}
+
+// String returns the specification of this backoff, which is the specification of the delegate
+// followed by the limit, e.g. "fixed=200,maxAttempts=5".
+func (f *AttemptLimitingBackoff) String() string {
+	return specOf(f.delegate) + ",maxAttempts=" + strconv.Itoa(f.limit)
+}
+
 // NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
 func (f *AttemptLimitingBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
 	if numAttemptsSoFar >= f.limit {
@@ -43,3 +62,11 @@ func (f *AttemptLimitingBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
 	}
 	return f.delegate.NextDelayMillis(numAttemptsSoFar)
 }
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *AttemptLimitingBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	if numAttemptsSoFar >= f.limit {
+		return -1
+	}
+	return f.durations.NextDelay(numAttemptsSoFar)
+}
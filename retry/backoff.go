@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 )
 
 // Backoff controls back off between attempts in a single retry operation.
@@ -28,6 +29,80 @@ type Backoff interface {
 	NextDelayMillis(numAttemptsSoFar int) int64
 }
 
+// DurationBackoff controls back off between attempts in a single retry operation, at a finer granularity than Backoff.
+// All backoffs of this package implement both Backoff and DurationBackoff.
+type DurationBackoff interface {
+	// NextDelay returns the duration to wait for before attempting a retry. A negative duration indicates no more retry.
+	NextDelay(numAttemptsSoFar int) time.Duration
+}
+
+// StatefulBackoff is a Backoff which keeps state across the attempts of a retry operation, e.g. DecorrelatedJitterBackoff.
+// Such a backoff must not be shared by concurrent retry operations.
+type StatefulBackoff interface {
+	Backoff
+	// Fresh returns a new instance of this backoff with initial state, to be used by a single retry operation.
+	Fresh() Backoff
+}
+
+// Fresh returns a fresh instance of given backoff for a single retry operation if it is a StatefulBackoff,
+// otherwise returns the backoff itself.
+func Fresh(b Backoff) Backoff {
+	if s, ok := b.(StatefulBackoff); ok {
+		return s.Fresh()
+	}
+	return b
+}
+
+// freshDurationBackoff returns a fresh instance of given backoff like Fresh, including a StatefulBackoff
+// adapted by AsDurationBackoff, which is made fresh before being adapted again.
+func freshDurationBackoff(b DurationBackoff) DurationBackoff {
+	if a, ok := b.(*millisBackoffAdapter); ok {
+		if s, ok := a.Backoff.(StatefulBackoff); ok {
+			return AsDurationBackoff(s.Fresh())
+		}
+		return b
+	}
+	if s, ok := b.(StatefulBackoff); ok {
+		return AsDurationBackoff(s.Fresh())
+	}
+	return b
+}
+
+// AsDurationBackoff returns given Backoff as a DurationBackoff, adapting it if it does not implement DurationBackoff.
+func AsDurationBackoff(b Backoff) DurationBackoff {
+	if d, ok := b.(DurationBackoff); ok || b == nil {
+		return d
+	}
+	return &millisBackoffAdapter{b}
+}
+
+// AsBackoff returns given DurationBackoff as a Backoff, adapting it if it does not implement Backoff.
+// The adapted delays are truncated to milliseconds.
+func AsBackoff(b DurationBackoff) Backoff {
+	if m, ok := b.(Backoff); ok || b == nil {
+		return m
+	}
+	return &durationBackoffAdapter{b}
+}
+
+// millisBackoffAdapter adapts a Backoff to DurationBackoff.
+type millisBackoffAdapter struct {
+	Backoff
+}
+
+func (a *millisBackoffAdapter) NextDelay(numAttemptsSoFar int) time.Duration {
+	return millisToDuration(a.NextDelayMillis(numAttemptsSoFar))
+}
+
+// durationBackoffAdapter adapts a DurationBackoff to Backoff.
+type durationBackoffAdapter struct {
+	DurationBackoff
+}
+
+func (a *durationBackoffAdapter) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(a.NextDelay(numAttemptsSoFar))
+}
+
 // BackoffBuilder is the builder for backoff.
 type BackoffBuilder struct {
 	layer []interface{}
@@ -65,9 +140,34 @@ func NewBackoffBuilder() *BackoffBuilder {
 //   // "random=minDelayMillis:maxDelayMillis" is for RandomBackoff.
 //   // minDelayMillis will be 0 if its omitted.
 //   // maxDelayMillis will be 200 if its omitted.
+//   //
+//   // "decorrelated=baseDelayMillis:maxDelayMillis" is for DecorrelatedJitterBackoff.
+//   // "equal=baseDelayMillis:maxDelayMillis" is for EqualJitterBackoff.
+//   // baseDelayMillis will be 200 if its omitted.
+//   // maxDelayMillis will be 10000 if its omitted.
+//   //
+//   // "fibonacci=initialDelayMillis:maxDelayMillis" is for FibonacciBackoff.
+//   // initialDelayMillis will be 200 if its omitted.
+//   // maxDelayMillis will be 10000 if its omitted.
+//   //
+//   // "linear=initialDelayMillis:incrementMillis:maxDelayMillis" is for LinearBackoff.
+//   // initialDelayMillis will be 200 if its omitted.
+//   // incrementMillis will be 200 if its omitted.
+//   // maxDelayMillis will be 10000 if its omitted.
 //
 // To omit a value, just make it blank but keep separation ':'.
 // For example: "exponential=12::3" means initialDelayMillis = 12, maxDelayMillis is default = 10000 and multiplier = 3
+//
+// Same as Armeria, the base backoff could be followed by comma-separated options, in any order:
+//   // "jitter=jitterRate" or "jitter=minJitterRate:maxJitterRate" adds a jitter, same as WithJitter and WithJitterBound.
+//   // minJitterRate will be -0.2 and maxJitterRate will be 0.2 if they are omitted.
+//   //
+//   // "maxAttempts=limit" limits the number of attempts, same as WithLimit.
+//
+// The jitter is added before limiting the number of attempts, e.g.
+// "exponential=200:10000:2.0,jitter=-0.1:0.3,maxAttempts=5". The String method of backoffs of this package
+// returns such a specification, which builds the same backoff again. Delays could have up to 6 fractional
+// digits of milliseconds, e.g. "fixed=0.5". A wrapped backoff which is not a fmt.Stringer has no specification.
 func (b *BackoffBuilder) BaseBackoffSpec(spec string) *BackoffBuilder {
 	b.spec = spec
 	return b
@@ -150,14 +250,68 @@ func (b *BackoffBuilder) Build() (r Backoff, err error) {
 }
 
 func parseFromSpec(spec string) (r Backoff, err error) {
-	index := strings.Index(spec, "=")
-	if index < 0 {
+	var jitter *withJitter
+	var limit *withLimit
+
+	for _, option := range strings.Split(spec, ",") {
+		index := strings.Index(option, "=")
+		if index < 0 {
+			err = ErrInvalidSpecFormat
+			return
+		}
+
+		// get key and values
+		key, values := strings.TrimSpace(option[:index]), strings.TrimSpace(option[index+1:])
+		switch key {
+		case "jitter": // jitter=jitterRate or jitter=minJitterRate:maxJitterRate
+			if jitter != nil {
+				err = ErrInvalidSpecFormat
+			} else {
+				jitter, err = parseJitter(values)
+			}
+
+		case "maxAttempts": // maxAttempts=limit
+			if limit != nil {
+				err = ErrInvalidSpecFormat
+			} else {
+				limit, err = parseMaxAttempts(values)
+			}
+
+		default:
+			if r != nil {
+				err = ErrInvalidSpecFormat
+			} else {
+				r, err = parseBaseFromSpec(key, values)
+			}
+		}
+
+		if err != nil {
+			r = nil
+			return
+		}
+	}
+
+	if r == nil {
 		err = ErrInvalidSpecFormat
 		return
 	}
 
-	// get key and values
-	key, values := spec[:index], spec[index+1:]
+	// same as Armeria, jitter is added before limiting the number of attempts
+	if jitter != nil {
+		if r, err = NewJitterAddingBackoff(r, jitter.minJitterRate, jitter.maxJitterRate); err != nil {
+			r = nil
+			return
+		}
+	}
+	if limit != nil {
+		if r, err = NewAttemptLimitingBackoff(r, limit.limit); err != nil {
+			r = nil
+		}
+	}
+	return
+}
+
+func parseBaseFromSpec(key, values string) (r Backoff, err error) {
 	switch key {
 	case "exponential": // exponential=initialDelayMillis:maxDelayMillis:multiplier
 		r, err = parseExponentialBackoff(values)
@@ -168,6 +322,18 @@ func parseFromSpec(spec string) (r Backoff, err error) {
 	case "random": // random=minDelayMillis:maxDelayMillis
 		r, err = parseRandomBackoff(values)
 
+	case "decorrelated": // decorrelated=baseDelayMillis:maxDelayMillis
+		r, err = parseDecorrelatedJitterBackoff(values)
+
+	case "equal": // equal=baseDelayMillis:maxDelayMillis
+		r, err = parseEqualJitterBackoff(values)
+
+	case "fibonacci": // fibonacci=initialDelayMillis:maxDelayMillis
+		r, err = parseFibonacciBackoff(values)
+
+	case "linear": // linear=initialDelayMillis:incrementMillis:maxDelayMillis
+		r, err = parseLinearBackoff(values)
+
 	default:
 		err = ErrInvalidSpecFormat
 	}
@@ -175,17 +341,57 @@ func parseFromSpec(spec string) (r Backoff, err error) {
 	return
 }
 
+// jitter=jitterRate or jitter=minJitterRate:maxJitterRate
+func parseJitter(values string) (r *withJitter, err error) {
+	r = &withJitter{minJitterRate: DefaultMinJitterRate, maxJitterRate: DefaultMaxJitterRate}
+
+	splited := strings.Split(values, ":")
+	switch len(splited) {
+	case 1:
+		if splited[0] != "" {
+			var jitterRate float64
+			if jitterRate, err = strconv.ParseFloat(splited[0], 64); err != nil {
+				return
+			}
+			r.minJitterRate, r.maxJitterRate = -jitterRate, jitterRate
+		}
+
+	case 2:
+		if splited[0] != "" {
+			if r.minJitterRate, err = strconv.ParseFloat(splited[0], 64); err != nil {
+				return
+			}
+		}
+		if splited[1] != "" {
+			r.maxJitterRate, err = strconv.ParseFloat(splited[1], 64)
+		}
+
+	default:
+		err = ErrInvalidSpecFormat
+	}
+	return
+}
+
+// maxAttempts=limit
+func parseMaxAttempts(values string) (r *withLimit, err error) {
+	limit, err := strconv.Atoi(values)
+	if err == nil {
+		r = &withLimit{limit: limit}
+	}
+	return
+}
+
 // fixed=delayMillis
 func parseFixedBackoff(values string) (r Backoff, err error) {
-	delayMillis := DefaultDelayMillis
+	delay := millisToDuration(DefaultDelayMillis)
 
 	if values != "" {
-		if delayMillis, err = strconv.ParseInt(values, 10, 64); err != nil {
+		if delay, err = parseMillis(values); err != nil {
 			return
 		}
 	}
 
-	r, err = NewFixedBackoff(delayMillis)
+	r, err = NewFixedDurationBackoff(delay)
 	return
 }
 
@@ -197,19 +403,19 @@ func parseRandomBackoff(values string) (r Backoff, err error) {
 		return
 	}
 
-	minDelayMillis, maxDelayMillis := DefaultMinDelayMillis, DefaultMaxDelayMillis
+	minDelay, maxDelay := millisToDuration(DefaultMinDelayMillis), millisToDuration(DefaultMaxDelayMillis)
 	if splited[0] != "" {
-		if minDelayMillis, err = strconv.ParseInt(splited[0], 10, 64); err != nil {
+		if minDelay, err = parseMillis(splited[0]); err != nil {
 			return
 		}
 	}
 	if splited[1] != "" {
-		if maxDelayMillis, err = strconv.ParseInt(splited[1], 10, 64); err != nil {
+		if maxDelay, err = parseMillis(splited[1]); err != nil {
 			return
 		}
 	}
 
-	r, err = NewRandomBackoff(minDelayMillis, maxDelayMillis)
+	r, err = NewRandomDurationBackoff(minDelay, maxDelay)
 	return
 }
 
@@ -221,14 +427,14 @@ func parseExponentialBackoff(values string) (r Backoff, err error) {
 		return
 	}
 
-	initialDelayMillis, maxDelayMillis, multiplier := DefaultInitialDelayMillis, DefaultMaxDelayMillis, DefaultMultiplier
+	initialDelay, maxDelay, multiplier := millisToDuration(DefaultInitialDelayMillis), millisToDuration(DefaultMaxDelayMillis), DefaultMultiplier
 	if splited[0] != "" {
-		if initialDelayMillis, err = strconv.ParseInt(splited[0], 10, 64); err != nil {
+		if initialDelay, err = parseMillis(splited[0]); err != nil {
 			return
 		}
 	}
 	if splited[1] != "" {
-		if maxDelayMillis, err = strconv.ParseInt(splited[1], 10, 64); err != nil {
+		if maxDelay, err = parseMillis(splited[1]); err != nil {
 			return
 		}
 	}
@@ -238,6 +444,88 @@ func parseExponentialBackoff(values string) (r Backoff, err error) {
 		}
 	}
 
-	r, err = NewExponentialBackoff(initialDelayMillis, maxDelayMillis, multiplier)
+	r, err = NewExponentialDurationBackoff(initialDelay, maxDelay, multiplier)
+	return
+}
+
+// decorrelated=baseDelayMillis:maxDelayMillis
+func parseDecorrelatedJitterBackoff(values string) (r Backoff, err error) {
+	baseDelay, maxDelay, err := parseDelayAndMaxDelay(values)
+	if err != nil {
+		return
+	}
+
+	r, err = NewDecorrelatedJitterDurationBackoff(baseDelay, maxDelay)
+	return
+}
+
+// equal=baseDelayMillis:maxDelayMillis
+func parseEqualJitterBackoff(values string) (r Backoff, err error) {
+	baseDelay, maxDelay, err := parseDelayAndMaxDelay(values)
+	if err != nil {
+		return
+	}
+
+	r, err = NewEqualJitterDurationBackoff(baseDelay, maxDelay)
+	return
+}
+
+// fibonacci=initialDelayMillis:maxDelayMillis
+func parseFibonacciBackoff(values string) (r Backoff, err error) {
+	initialDelay, maxDelay, err := parseDelayAndMaxDelay(values)
+	if err != nil {
+		return
+	}
+
+	r, err = NewFibonacciDurationBackoff(initialDelay, maxDelay)
+	return
+}
+
+// linear=initialDelayMillis:incrementMillis:maxDelayMillis
+func parseLinearBackoff(values string) (r Backoff, err error) {
+	splited := strings.Split(values, ":")
+	if len(splited) != 3 {
+		err = ErrInvalidSpecFormat
+		return
+	}
+
+	initialDelay, increment, maxDelay := millisToDuration(DefaultInitialDelayMillis), millisToDuration(DefaultIncrementMillis), millisToDuration(DefaultMaxDelayMillis)
+	if splited[0] != "" {
+		if initialDelay, err = parseMillis(splited[0]); err != nil {
+			return
+		}
+	}
+	if splited[1] != "" {
+		if increment, err = parseMillis(splited[1]); err != nil {
+			return
+		}
+	}
+	if splited[2] != "" {
+		if maxDelay, err = parseMillis(splited[2]); err != nil {
+			return
+		}
+	}
+
+	r, err = NewLinearDurationBackoff(initialDelay, increment, maxDelay)
+	return
+}
+
+// delayMillis:maxDelayMillis
+func parseDelayAndMaxDelay(values string) (delay, maxDelay time.Duration, err error) {
+	splited := strings.Split(values, ":")
+	if len(splited) != 2 {
+		err = ErrInvalidSpecFormat
+		return
+	}
+
+	delay, maxDelay = millisToDuration(DefaultInitialDelayMillis), millisToDuration(DefaultMaxDelayMillis)
+	if splited[0] != "" {
+		if delay, err = parseMillis(splited[0]); err != nil {
+			return
+		}
+	}
+	if splited[1] != "" {
+		maxDelay, err = parseMillis(splited[1])
+	}
 	return
 }
@@ -15,7 +15,10 @@
 package retry
 
 import (
+	"fmt"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestBackoffBuilder(t *testing.T) {
@@ -148,6 +151,13 @@ func TestParseInvalidSpec(t *testing.T) {
 	if _, err := parseFromSpec("exponential=1:2:a"); err == nil {
 		t.Fatal()
 	}
+
+	// fractional milliseconds have 1 to 6 digits
+	for _, spec := range []string{"fixed=1.", "fixed=.5", "fixed=1.1234567", "fixed=1.a", "fixed=1.-5", "random=0:1.2.3"} {
+		if _, err := parseFromSpec(spec); err == nil {
+			t.Fatal(spec)
+		}
+	}
 }
 
 func TestParseSpec(t *testing.T) {
@@ -184,11 +194,309 @@ func TestParseSpec(t *testing.T) {
 			t.Fatal(err)
 		} else {
 			tmp := b.(*ExponentialBackoff)
-			if tmp.initialDelayMillis != expectedinitialDelayMillis[i] ||
-				tmp.maxDelayMillis != expectedMaxDelayMillis[i] ||
+			if tmp.initialDelay != millisToDuration(expectedinitialDelayMillis[i]) ||
+				tmp.maxDelay != millisToDuration(expectedMaxDelayMillis[i]) ||
 				tmp.multiplier != expectedMultipiler[i] {
 				t.Fatal()
 			}
 		}
 	}
 }
+
+type millisOnlyBackoff struct{ delayMillis int64 }
+
+func (m millisOnlyBackoff) NextDelayMillis(numAttemptsSoFar int) int64 { return m.delayMillis }
+
+type durationOnlyBackoff struct{ delay time.Duration }
+
+func (d durationOnlyBackoff) NextDelay(numAttemptsSoFar int) time.Duration { return d.delay }
+
+func TestDurationBackoff(t *testing.T) {
+	fixed, _ := NewFixedDurationBackoff(500 * time.Microsecond)
+	exponential, _ := NewExponentialDurationBackoff(100*time.Microsecond, time.Millisecond, 2)
+	random, _ := NewRandomDurationBackoff(100*time.Microsecond, 200*time.Microsecond)
+	limiting, _ := NewAttemptLimitingBackoff(fixed, 2)
+	jitter, _ := NewJitterAddingBackoff(fixed, -0.5, 0.5)
+
+	for _, b := range []Backoff{fixed, exponential, random, limiting, jitter, NoDelayBackoff, NoRetry} {
+		if AsDurationBackoff(b) != b.(DurationBackoff) || AsBackoff(b.(DurationBackoff)) != b {
+			t.Fatal(b)
+		}
+	}
+
+	if fixed.NextDelay(1) != 500*time.Microsecond || fixed.NextDelayMillis(1) != 0 {
+		t.Fatal()
+	}
+	if exponential.NextDelay(1) != 100*time.Microsecond || exponential.NextDelay(3) != 400*time.Microsecond ||
+		exponential.NextDelay(10) != time.Millisecond || exponential.NextDelayMillis(10) != 1 {
+		t.Fatal()
+	}
+	for i := 0; i < 1000; i++ {
+		if d := random.NextDelay(i); d < 100*time.Microsecond || d > 200*time.Microsecond {
+			t.Fatal(d)
+		}
+		if d := jitter.NextDelay(i); d < 250*time.Microsecond || d > 750*time.Microsecond {
+			t.Fatal(d)
+		}
+	}
+	if limiting.NextDelay(1) != 500*time.Microsecond || limiting.NextDelay(2) >= 0 {
+		t.Fatal()
+	}
+	if NoDelayBackoff.(DurationBackoff).NextDelay(1) != 0 || NoRetry.(DurationBackoff).NextDelay(1) >= 0 || NoRetry.NextDelayMillis(1) != -1 {
+		t.Fatal()
+	}
+
+	if _, err := NewFixedDurationBackoff(-1); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewExponentialDurationBackoff(-1, 1, 2); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewExponentialDurationBackoff(2, 1, 2); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewExponentialDurationBackoff(1, 2, 1); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewRandomDurationBackoff(-1, 1); err == nil {
+		t.Fatal()
+	}
+	if _, err := NewRandomDurationBackoff(2, 1); err == nil {
+		t.Fatal()
+	}
+}
+
+func TestBackoffAdapters(t *testing.T) {
+	if AsDurationBackoff(nil) != nil || AsBackoff(nil) != nil {
+		t.Fatal()
+	}
+
+	if d := AsDurationBackoff(millisOnlyBackoff{12}); d.NextDelay(1) != 12*time.Millisecond {
+		t.Fatal()
+	} else if AsBackoff(d).NextDelayMillis(1) != 12 {
+		t.Fatal()
+	}
+	if AsDurationBackoff(millisOnlyBackoff{-1}).NextDelay(1) >= 0 {
+		t.Fatal()
+	}
+	if AsDurationBackoff(millisOnlyBackoff{math.MaxInt64}).NextDelay(1) != math.MaxInt64 {
+		t.Fatal()
+	}
+
+	if b := AsBackoff(durationOnlyBackoff{1500 * time.Microsecond}); b.NextDelayMillis(1) != 1 {
+		t.Fatal()
+	} else if AsDurationBackoff(b).NextDelay(1) != 1500*time.Microsecond {
+		t.Fatal()
+	}
+	if AsBackoff(durationOnlyBackoff{-1}).NextDelayMillis(1) != -1 {
+		t.Fatal()
+	}
+
+	// wrapping backoffs keep sub-millisecond delays of delegates
+	limiting, _ := NewAttemptLimitingBackoff(AsBackoff(durationOnlyBackoff{100 * time.Microsecond}), 3)
+	if limiting.NextDelay(1) != 100*time.Microsecond || limiting.NextDelay(3) >= 0 {
+		t.Fatal()
+	}
+}
+
+func TestParseJitterSpec(t *testing.T) {
+	if b, err := parseFromSpec("decorrelated=10:20"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*DecorrelatedJitterBackoff); tmp.baseDelay != 10*time.Millisecond || tmp.maxDelay != 20*time.Millisecond {
+		t.Fatal()
+	}
+
+	if b, err := parseFromSpec("decorrelated=:"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*DecorrelatedJitterBackoff); tmp.baseDelay != millisToDuration(DefaultInitialDelayMillis) ||
+		tmp.maxDelay != millisToDuration(DefaultMaxDelayMillis) {
+		t.Fatal()
+	}
+
+	if b, err := parseFromSpec("equal=:300"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*EqualJitterBackoff); tmp.baseDelay != millisToDuration(DefaultInitialDelayMillis) || tmp.maxDelay != 300*time.Millisecond {
+		t.Fatal()
+	}
+
+	for _, spec := range []string{"decorrelated=", "decorrelated=1", "equal=1:2:3"} {
+		if _, err := parseFromSpec(spec); err != ErrInvalidSpecFormat {
+			t.Fatal(spec, err)
+		}
+	}
+	for _, spec := range []string{"decorrelated=a:2", "decorrelated=1:a", "decorrelated=0:2", "equal=3:2"} {
+		if _, err := parseFromSpec(spec); err == nil {
+			t.Fatal(spec)
+		}
+	}
+}
+
+func TestParseFibonacciAndLinearSpec(t *testing.T) {
+	if b, err := parseFromSpec("fibonacci=10:20"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*FibonacciBackoff); tmp.initialDelay != 10*time.Millisecond || tmp.maxDelay != 20*time.Millisecond {
+		t.Fatal()
+	}
+
+	if b, err := parseFromSpec("fibonacci=:"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*FibonacciBackoff); tmp.initialDelay != millisToDuration(DefaultInitialDelayMillis) ||
+		tmp.maxDelay != millisToDuration(DefaultMaxDelayMillis) {
+		t.Fatal()
+	}
+
+	if b, err := parseFromSpec("linear=10:5:20"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*LinearBackoff); tmp.initialDelay != 10*time.Millisecond || tmp.increment != 5*time.Millisecond ||
+		tmp.maxDelay != 20*time.Millisecond {
+		t.Fatal()
+	}
+
+	if b, err := parseFromSpec("linear=::"); err != nil {
+		t.Fatal(err)
+	} else if tmp := b.(*LinearBackoff); tmp.initialDelay != millisToDuration(DefaultInitialDelayMillis) ||
+		tmp.increment != millisToDuration(DefaultIncrementMillis) || tmp.maxDelay != millisToDuration(DefaultMaxDelayMillis) {
+		t.Fatal()
+	}
+
+	for _, spec := range []string{"fibonacci=", "fibonacci=1:2:3", "linear=1:2", "linear="} {
+		if _, err := parseFromSpec(spec); err != ErrInvalidSpecFormat {
+			t.Fatal(spec, err)
+		}
+	}
+	for _, spec := range []string{"fibonacci=a:2", "fibonacci=3:2", "linear=a:1:2", "linear=1:a:2", "linear=1:1:a", "linear=3:1:2"} {
+		if _, err := parseFromSpec(spec); err == nil {
+			t.Fatal(spec)
+		}
+	}
+}
+
+func TestParseFullSpec(t *testing.T) {
+	b, err := NewBackoffBuilder().BaseBackoffSpec("exponential=200:10000:2.0,jitter=0.2,maxAttempts=5").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiting := b.(*AttemptLimitingBackoff)
+	jitter := limiting.delegate.(*JitterAddingBackoff)
+	exponential := jitter.delegate.(*ExponentialBackoff)
+	if limiting.limit != 5 || jitter.minJitterRate != -0.2 || jitter.maxJitterRate != 0.2 ||
+		exponential.initialDelay != 200*time.Millisecond || exponential.maxDelay != 10*time.Second || exponential.multiplier != 2 {
+		t.Fatal(b)
+	}
+
+	// options in any order, with jitter bounds and spaces
+	b, err = parseFromSpec(" maxAttempts=3 , jitter=-0.1:0.3, fixed=100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiting = b.(*AttemptLimitingBackoff)
+	jitter = limiting.delegate.(*JitterAddingBackoff)
+	if limiting.limit != 3 || jitter.minJitterRate != -0.1 || jitter.maxJitterRate != 0.3 || jitter.delegate.(*FixedBackoff).delay != 100*time.Millisecond {
+		t.Fatal(b)
+	}
+
+	// default jitter rates
+	for _, spec := range []string{"fixed=1,jitter=", "fixed=1,jitter=:"} {
+		if b, err = parseFromSpec(spec); err != nil {
+			t.Fatal(err)
+		} else if jitter = b.(*JitterAddingBackoff); jitter.minJitterRate != DefaultMinJitterRate || jitter.maxJitterRate != DefaultMaxJitterRate {
+			t.Fatal(spec)
+		}
+	}
+
+	for _, spec := range []string{
+		"", ",", "fixed=1,", "jitter=0.2", "maxAttempts=3", "fixed=1,random=1:2", "fixed=1,jitter=0.1,jitter=0.2",
+		"fixed=1,maxAttempts=1,maxAttempts=2", "fixed=1,jitter=1:2:3", "fixed=1,unknown=1",
+	} {
+		if _, err = parseFromSpec(spec); err != ErrInvalidSpecFormat {
+			t.Fatal(spec, err)
+		}
+	}
+	for _, spec := range []string{"fixed=1,jitter=a", "fixed=1,jitter=a:1", "fixed=1,jitter=0:a", "fixed=1,jitter=2", "fixed=1,maxAttempts=", "fixed=1,maxAttempts=0"} {
+		if b, err = parseFromSpec(spec); err == nil || b != nil {
+			t.Fatal(spec)
+		}
+	}
+}
+
+func TestBackoffString(t *testing.T) {
+	for _, spec := range []string{
+		"exponential=200:10000:2.5",
+		"fixed=200",
+		"random=0:200",
+		"decorrelated=100:5000",
+		"equal=100:5000",
+		"fibonacci=100:5000",
+		"linear=100:50:5000",
+		"exponential=200:10000:2,jitter=0.2,maxAttempts=5",
+		"fixed=100,jitter=-0.1:0.3",
+		"linear=100:50:5000,maxAttempts=3",
+	} {
+		b, err := NewBackoffBuilder().BaseBackoffSpec(spec).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := b.(fmt.Stringer).String(); s != spec {
+			t.Fatal(s, spec)
+		}
+		if again, err := parseFromSpec(spec); err != nil || fmt.Sprint(again) != spec {
+			t.Fatal(again, err)
+		}
+	}
+
+	// layers are serialized in the order they wrap the base backoff
+	fixed, _ := NewFixedBackoff(10)
+	b, _ := NewBackoffBuilder().BaseBackoff(fixed).WithLimit(2).WithJitterBound(0, 0.5).Build()
+	if s := fmt.Sprint(b); s != "fixed=10,maxAttempts=2,jitter=0:0.5" {
+		t.Fatal(s)
+	}
+
+	// no retry is equivalent to a single attempt
+	if s := fmt.Sprint(NoRetry); s != "fixed=0,maxAttempts=1" {
+		t.Fatal(s)
+	}
+	if b, err := NewBackoffBuilder().BaseBackoffSpec(fmt.Sprint(NoRetry)).Build(); err != nil || b.NextDelayMillis(1) >= 0 {
+		t.Fatal(b, err)
+	}
+
+	// every strategy round-trips, even with delays which are not whole milliseconds
+	fixed, _ = NewFixedDurationBackoff(1500 * time.Microsecond)
+	random, _ := NewRandomDurationBackoff(time.Nanosecond, 2*time.Millisecond+10*time.Microsecond)
+	exponential, _ := NewExponentialDurationBackoff(500*time.Microsecond, 10*time.Millisecond, 2)
+	decorrelated, _ := NewDecorrelatedJitterDurationBackoff(250*time.Microsecond, 5*time.Millisecond)
+	equal, _ := NewEqualJitterDurationBackoff(100*time.Microsecond, 5*time.Millisecond)
+	fibonacci, _ := NewFibonacciDurationBackoff(1*time.Microsecond, 5*time.Millisecond)
+	linear, _ := NewLinearDurationBackoff(100*time.Microsecond, 50*time.Microsecond, 5*time.Millisecond)
+	wrapped, _ := NewBackoffBuilder().BaseBackoff(fixed).WithJitterBound(0, 0.5).WithLimit(3).Build()
+	for b, expected := range map[fmt.Stringer]string{
+		fixed:                  "fixed=1.5",
+		random:                 "random=0.000001:2.01",
+		exponential:            "exponential=0.5:10:2",
+		decorrelated:           "decorrelated=0.25:5",
+		equal:                  "equal=0.1:5",
+		fibonacci:              "fibonacci=0.001:5",
+		linear:                 "linear=0.1:0.05:5",
+		wrapped.(fmt.Stringer): "fixed=1.5,jitter=0:0.5,maxAttempts=3",
+		NoRetry.(fmt.Stringer): "fixed=0,maxAttempts=1",
+	} {
+		if s := b.String(); s != expected {
+			t.Fatal(s, expected)
+		}
+		again, err := NewBackoffBuilder().BaseBackoffSpec(b.String()).Build()
+		if err != nil || fmt.Sprint(again) != expected {
+			t.Fatal(again, err)
+		}
+	}
+	if b, _ := NewBackoffBuilder().BaseBackoffSpec("fixed=1.5").Build(); AsDurationBackoff(b).NextDelay(1) != 1500*time.Microsecond {
+		t.Fatal()
+	}
+
+	// wrapped backoffs which are not fmt.Stringer have no specification
+	limiting, _ := NewAttemptLimitingBackoff(millisOnlyBackoff{1}, 2)
+	if s := limiting.String(); s != "retry.millisOnlyBackoff,maxAttempts=2" {
+		t.Fatal(s)
+	}
+	if _, err := NewBackoffBuilder().BaseBackoffSpec(limiting.String()).Build(); err == nil {
+		t.Fatal()
+	}
+}
@@ -25,6 +25,8 @@ const (
 	DefaultMinDelayMillis int64 = 0
 	// DefaultMaxDelayMillis is default max delay millis.
 	DefaultMaxDelayMillis int64 = 10000
+	// DefaultIncrementMillis is default increment millis of LinearBackoff.
+	DefaultIncrementMillis int64 = 200
 	// DefaultMultiplier is default multiplier.
 	DefaultMultiplier float64 = 2.0
 	// DefaultMinJitterRate is default min jitter rate.
@@ -0,0 +1,91 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DecorrelatedJitterBackoff waits for a random amount of time between attempts, which grows with the previous delay,
+// using https://www.awsarchitectureblog.com/2015/03/backoff.html decorrelated jitter strategy:
+// delay = min(maxDelay, random(baseDelay, previousDelay * 3)).
+//
+// The previous delay is kept in the backoff, and reset on the first retry, i.e. when numAttemptsSoFar is 1.
+// Concurrent retry operations must not share an instance: create a fresh one per operation by Fresh.
+type DecorrelatedJitterBackoff struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	prev      int64 // previous delay in nanoseconds
+}
+
+// NewDecorrelatedJitterBackoff creates new DecorrelatedJitterBackoff.
+func NewDecorrelatedJitterBackoff(baseDelayMillis, maxDelayMillis int64) (b *DecorrelatedJitterBackoff, err error) {
+	if baseDelayMillis <= 0 {
+		err = fmt.Errorf("baseDelayMillis: %d (expected: > 0)", baseDelayMillis)
+	} else if baseDelayMillis > maxDelayMillis {
+		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, baseDelayMillis)
+	} else {
+		b = newDecorrelatedJitterBackoff(millisToDuration(baseDelayMillis), millisToDuration(maxDelayMillis))
+	}
+	return
+}
+
+// NewDecorrelatedJitterDurationBackoff creates new DecorrelatedJitterBackoff, with delays which could be less than a millisecond.
+func NewDecorrelatedJitterDurationBackoff(baseDelay, maxDelay time.Duration) (b *DecorrelatedJitterBackoff, err error) {
+	if baseDelay <= 0 {
+		err = fmt.Errorf("baseDelay: %v (expected: > 0)", baseDelay)
+	} else if baseDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, baseDelay)
+	} else {
+		b = newDecorrelatedJitterBackoff(baseDelay, maxDelay)
+	}
+	return
+}
+
+func newDecorrelatedJitterBackoff(baseDelay, maxDelay time.Duration) *DecorrelatedJitterBackoff {
+	return &DecorrelatedJitterBackoff{baseDelay: baseDelay, maxDelay: maxDelay, prev: int64(baseDelay)}
+}
+
+// Fresh returns a new DecorrelatedJitterBackoff with the same delays, which starts from baseDelay.
+func (f *DecorrelatedJitterBackoff) Fresh() Backoff {
+	return newDecorrelatedJitterBackoff(f.baseDelay, f.maxDelay)
+}
+
+// String returns the specification of this backoff, e.g. "decorrelated=200:10000".
+func (f *DecorrelatedJitterBackoff) String() string {
+	return "decorrelated=" + formatMillis(f.baseDelay) + ":" + formatMillis(f.maxDelay)
+}
+
+// NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
+func (f *DecorrelatedJitterBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *DecorrelatedJitterBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	prev := atomic.LoadInt64(&f.prev)
+	if numAttemptsSoFar <= 1 {
+		prev = int64(f.baseDelay)
+	}
+
+	next := int64(f.baseDelay) + nextRandomInt64IncludingZero(saturatedMultiply(prev, 3)-int64(f.baseDelay))
+	if next > int64(f.maxDelay) {
+		next = int64(f.maxDelay)
+	}
+	atomic.StoreInt64(&f.prev, next)
+	return time.Duration(next)
+}
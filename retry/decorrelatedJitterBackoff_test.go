@@ -0,0 +1,103 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	if _, err := NewDecorrelatedJitterBackoff(0, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewDecorrelatedJitterBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewDecorrelatedJitterDurationBackoff(0, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewDecorrelatedJitterDurationBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	b, err := NewDecorrelatedJitterBackoff(100, 1000)
+	if err != nil || b == nil {
+		t.FailNow()
+	}
+
+	// bounds are checked against durations, since the backoff grows from the untruncated previous delay
+	reachedMax := false
+	for i := 0; i < 100; i++ {
+		prev := 100 * time.Millisecond
+		for attempt := 1; attempt < 10; attempt++ {
+			d := b.NextDelay(attempt)
+			if upper := 3 * prev; d < 100*time.Millisecond || (d > upper && d != time.Second) || d > time.Second {
+				t.Fatal(attempt, prev, d)
+			}
+			reachedMax = reachedMax || d == time.Second
+			prev = d
+		}
+	}
+	if !reachedMax {
+		t.FailNow()
+	}
+
+	// the first retry starts from base delay again
+	b.NextDelayMillis(8)
+	if d := b.NextDelayMillis(1); d < 100 || d > 300 {
+		t.Fatal(d)
+	}
+
+	if b, _ = NewDecorrelatedJitterDurationBackoff(10*time.Microsecond, time.Millisecond); b.NextDelayMillis(1) != 0 {
+		t.FailNow()
+	} else if d := b.NextDelay(1); d < 10*time.Microsecond || d > 30*time.Microsecond {
+		t.Fatal(d)
+	}
+}
+
+func TestDecorrelatedJitterBackoff_Fresh(t *testing.T) {
+	b, _ := NewDecorrelatedJitterBackoff(100, 100000)
+	for attempt := 1; attempt < 10; attempt++ {
+		b.NextDelayMillis(attempt)
+	}
+
+	fresh := Fresh(b).(*DecorrelatedJitterBackoff)
+	if fresh == b || fresh.prev != int64(100*time.Millisecond) || fresh.baseDelay != b.baseDelay || fresh.maxDelay != b.maxDelay {
+		t.FailNow()
+	}
+
+	// wrapping backoffs are made fresh with their delegates
+	limiting, _ := NewAttemptLimitingBackoff(b, 3)
+	jitter, _ := NewJitterAddingBackoff(limiting, -0.1, 0.1)
+	freshJitter := Fresh(jitter).(*JitterAddingBackoff)
+	freshLimiting := freshJitter.delegate.(*AttemptLimitingBackoff)
+	if freshJitter == jitter || freshLimiting == limiting || freshLimiting.limit != 3 ||
+		freshLimiting.delegate == b || freshLimiting.durations != freshLimiting.delegate.(DurationBackoff) {
+		t.FailNow()
+	}
+
+	// stateless backoffs are not copied
+	fixed, _ := NewFixedBackoff(1)
+	if limiting, _ = NewAttemptLimitingBackoff(fixed, 3); Fresh(limiting) != limiting || Fresh(fixed) != fixed {
+		t.FailNow()
+	}
+	if jitter, _ = NewJitterAddingBackoff(fixed, 0, 0); Fresh(jitter) != jitter {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EqualJitterBackoff waits for an exponentially-increasing amount of time between attempts, keeping half of it
+// and adding a random jitter value up to the other half, using https://www.awsarchitectureblog.com/2015/03/backoff.html
+// equal jitter strategy: temp = min(maxDelay, baseDelay * 2^(numAttemptsSoFar-1)), delay = temp/2 + random(0, temp/2).
+type EqualJitterBackoff struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// NewEqualJitterBackoff creates new EqualJitterBackoff.
+func NewEqualJitterBackoff(baseDelayMillis, maxDelayMillis int64) (b *EqualJitterBackoff, err error) {
+	if baseDelayMillis < 0 {
+		err = fmt.Errorf("baseDelayMillis: %d (expected: >= 0)", baseDelayMillis)
+	} else if baseDelayMillis > maxDelayMillis {
+		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, baseDelayMillis)
+	} else {
+		b = &EqualJitterBackoff{baseDelay: millisToDuration(baseDelayMillis), maxDelay: millisToDuration(maxDelayMillis)}
+	}
+	return
+}
+
+// NewEqualJitterDurationBackoff creates new EqualJitterBackoff, with delays which could be less than a millisecond.
+func NewEqualJitterDurationBackoff(baseDelay, maxDelay time.Duration) (b *EqualJitterBackoff, err error) {
+	if baseDelay < 0 {
+		err = fmt.Errorf("baseDelay: %v (expected: >= 0)", baseDelay)
+	} else if baseDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, baseDelay)
+	} else {
+		b = &EqualJitterBackoff{baseDelay: baseDelay, maxDelay: maxDelay}
+	}
+	return
+}
+
+// String returns the specification of this backoff, e.g. "equal=200:10000".
+func (f *EqualJitterBackoff) String() string {
+	return "equal=" + formatMillis(f.baseDelay) + ":" + formatMillis(f.maxDelay)
+}
+
+// NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
+func (f *EqualJitterBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *EqualJitterBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	temp := time.Duration(saturatedMultiply(int64(f.baseDelay), math.Pow(2, float64(numAttemptsSoFar-1))))
+	if temp > f.maxDelay {
+		temp = f.maxDelay
+	}
+
+	half := temp / 2
+	return temp - half + time.Duration(nextRandomInt64IncludingZero(int64(half)))
+}
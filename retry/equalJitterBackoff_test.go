@@ -0,0 +1,64 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEqualJitterBackoff(t *testing.T) {
+	if _, err := NewEqualJitterBackoff(-1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewEqualJitterBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewEqualJitterDurationBackoff(-1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewEqualJitterDurationBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	b, err := NewEqualJitterBackoff(100, 1000)
+	if err != nil || b == nil {
+		t.FailNow()
+	}
+
+	for i := 0; i < 1000; i++ {
+		for attempt, temp := range []int64{0, 100, 200, 400, 800, 1000, 1000} {
+			if attempt == 0 {
+				continue
+			}
+			if d := b.NextDelayMillis(attempt); d < temp/2 || d > temp {
+				t.Fatal(attempt, d)
+			}
+		}
+	}
+
+	if b, _ = NewEqualJitterDurationBackoff(10*time.Microsecond, time.Millisecond); b.NextDelayMillis(1) != 0 {
+		t.FailNow()
+	} else if d := b.NextDelay(2); d < 10*time.Microsecond || d > 20*time.Microsecond {
+		t.Fatal(d)
+	}
+
+	if b, _ = NewEqualJitterBackoff(0, 0); b.NextDelayMillis(3) != 0 {
+		t.FailNow()
+	}
+}
@@ -17,13 +17,14 @@ package retry
 import (
 	"fmt"
 	"math"
+	"time"
 )
 
 // ExponentialBackoff waits for an exponentially-increasing amount of time between attempts.
 type ExponentialBackoff struct {
-	initialDelayMillis int64
-	maxDelayMillis     int64
-	multiplier         float64
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   float64
 }
 
 // NewExponentialBackoff creates new ExponentialBackoff.
@@ -36,23 +37,51 @@ func NewExponentialBackoff(initialDelayMillis, maxDelayMillis int64, multiplier
 		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, initialDelayMillis)
 	} else {
 		b = &ExponentialBackoff{
-			initialDelayMillis: initialDelayMillis,
-			maxDelayMillis:     maxDelayMillis,
-			multiplier:         multiplier,
+			initialDelay: millisToDuration(initialDelayMillis),
+			maxDelay:     millisToDuration(maxDelayMillis),
+			multiplier:   multiplier,
 		}
 	}
 	return
 }
 
+// NewExponentialDurationBackoff creates new ExponentialBackoff, with delays which could be less than a millisecond.
+func NewExponentialDurationBackoff(initialDelay, maxDelay time.Duration, multiplier float64) (b *ExponentialBackoff, err error) {
+	if multiplier <= 1 {
+		err = fmt.Errorf("multiplier: %.3f (expected: > 1.0)", multiplier)
+	} else if initialDelay < 0 {
+		err = fmt.Errorf("initialDelay: %v (expected: >= 0)", initialDelay)
+	} else if initialDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, initialDelay)
+	} else {
+		b = &ExponentialBackoff{
+			initialDelay: initialDelay,
+			maxDelay:     maxDelay,
+			multiplier:   multiplier,
+		}
+	}
+	return
+}
+
+// String returns the specification of this backoff, e.g. "exponential=200:10000:2".
+func (f *ExponentialBackoff) String() string {
+	return "exponential=" + formatMillis(f.initialDelay) + ":" + formatMillis(f.maxDelay) + ":" + formatFloat(f.multiplier)
+}
+
 // NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
-func (f *ExponentialBackoff) NextDelayMillis(numAttemptsSoFar int) (nextDelay int64) {
+func (f *ExponentialBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *ExponentialBackoff) NextDelay(numAttemptsSoFar int) (nextDelay time.Duration) {
 	if numAttemptsSoFar == 1 {
-		return f.initialDelayMillis
+		return f.initialDelay
 	}
 
-	nextDelay = saturatedMultiply(f.initialDelayMillis, math.Pow(f.multiplier, float64(numAttemptsSoFar-1)))
-	if nextDelay > f.maxDelayMillis {
-		nextDelay = f.maxDelayMillis
+	nextDelay = time.Duration(saturatedMultiply(int64(f.initialDelay), math.Pow(f.multiplier, float64(numAttemptsSoFar-1))))
+	if nextDelay > f.maxDelay {
+		nextDelay = f.maxDelay
 	}
 	return
 }
@@ -0,0 +1,82 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FibonacciBackoff waits for an amount of time growing along the Fibonacci sequence between attempts:
+// initialDelay, initialDelay, 2 * initialDelay, 3 * initialDelay, 5 * initialDelay and so on, up to maxDelay.
+type FibonacciBackoff struct {
+	initialDelay time.Duration
+	maxDelay     time.Duration
+}
+
+// NewFibonacciBackoff creates new FibonacciBackoff.
+func NewFibonacciBackoff(initialDelayMillis, maxDelayMillis int64) (b *FibonacciBackoff, err error) {
+	if initialDelayMillis < 0 {
+		err = fmt.Errorf("initialDelayMillis: %d (expected: >= 0)", initialDelayMillis)
+	} else if initialDelayMillis > maxDelayMillis {
+		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, initialDelayMillis)
+	} else {
+		b = &FibonacciBackoff{initialDelay: millisToDuration(initialDelayMillis), maxDelay: millisToDuration(maxDelayMillis)}
+	}
+	return
+}
+
+// NewFibonacciDurationBackoff creates new FibonacciBackoff, with delays which could be less than a millisecond.
+func NewFibonacciDurationBackoff(initialDelay, maxDelay time.Duration) (b *FibonacciBackoff, err error) {
+	if initialDelay < 0 {
+		err = fmt.Errorf("initialDelay: %v (expected: >= 0)", initialDelay)
+	} else if initialDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, initialDelay)
+	} else {
+		b = &FibonacciBackoff{initialDelay: initialDelay, maxDelay: maxDelay}
+	}
+	return
+}
+
+// String returns the specification of this backoff, e.g. "fibonacci=200:10000".
+func (f *FibonacciBackoff) String() string {
+	return "fibonacci=" + formatMillis(f.initialDelay) + ":" + formatMillis(f.maxDelay)
+}
+
+// NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
+func (f *FibonacciBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *FibonacciBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	if f.initialDelay == 0 {
+		return 0
+	}
+
+	prev, next := time.Duration(0), f.initialDelay
+	for i := 1; i < numAttemptsSoFar && next < f.maxDelay; i++ {
+		if prev > math.MaxInt64-next {
+			return f.maxDelay
+		}
+		prev, next = next, prev+next
+	}
+
+	if next > f.maxDelay {
+		return f.maxDelay
+	}
+	return next
+}
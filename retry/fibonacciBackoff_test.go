@@ -0,0 +1,66 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFibonacciBackoff(t *testing.T) {
+	if _, err := NewFibonacciBackoff(-1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewFibonacciBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewFibonacciDurationBackoff(-1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewFibonacciDurationBackoff(3, 2); err == nil {
+		t.FailNow()
+	}
+
+	b, err := NewFibonacciBackoff(100, 1000)
+	if err != nil || b == nil {
+		t.FailNow()
+	}
+
+	for i, expected := range []int64{100, 100, 200, 300, 500, 800, 1000, 1000} {
+		if d := b.NextDelayMillis(i + 1); d != expected {
+			t.Fatal(i+1, d)
+		}
+	}
+
+	if b.NextDelayMillis(math.MaxInt32) != 1000 {
+		t.FailNow()
+	}
+
+	if b, _ = NewFibonacciBackoff(0, 100); b.NextDelayMillis(10) != 0 {
+		t.FailNow()
+	}
+
+	if b, _ = NewFibonacciBackoff(1, math.MaxInt64); b.NextDelay(200) != math.MaxInt64 {
+		t.FailNow()
+	}
+
+	if b, _ = NewFibonacciDurationBackoff(100*time.Microsecond, time.Millisecond); b.NextDelay(4) != 300*time.Microsecond {
+		t.FailNow()
+	}
+}
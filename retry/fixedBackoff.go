@@ -16,31 +16,56 @@ package retry
 
 import (
 	"fmt"
+	"time"
 )
 
 // FixedBackoff waits for a fixed delay between attempts.
 type FixedBackoff struct {
-	delayMillis int64
+	delay time.Duration
 }
 
 // NewFixedBackoff creates new fixed backoff.
 func NewFixedBackoff(delayMillis int64) (b *FixedBackoff, err error) {
 	if delayMillis >= 0 {
-		b = &FixedBackoff{delayMillis: delayMillis}
+		b = &FixedBackoff{delay: millisToDuration(delayMillis)}
 	} else {
 		err = fmt.Errorf("delayMillis: %d (expected: >= 0)", delayMillis)
 	}
 	return
 }
 
+// NewFixedDurationBackoff creates new fixed backoff, with a delay which could be less than a millisecond.
+func NewFixedDurationBackoff(delay time.Duration) (b *FixedBackoff, err error) {
+	if delay >= 0 {
+		b = &FixedBackoff{delay: delay}
+	} else {
+		err = fmt.Errorf("delay: %v (expected: >= 0)", delay)
+	}
+	return
+}
+
+// String returns the specification of this backoff, e.g. "fixed=200".
+// NoRetry is specified as "fixed=0,maxAttempts=1", which never retries either.
+func (f *FixedBackoff) String() string {
+	if f.delay < 0 {
+		return "fixed=0,maxAttempts=1"
+	}
+	return "fixed=" + formatMillis(f.delay)
+}
+
 // NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
 func (f *FixedBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
-	return f.delayMillis
+	return durationToMillis(f.delay)
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *FixedBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	return f.delay
 }
 
 // NoDelayBackoff returns a Backoff that will never wait between attempts.
 // In most cases, using Backoff without delay is very dangerous.
-var NoDelayBackoff Backoff = &FixedBackoff{delayMillis: 0}
+var NoDelayBackoff Backoff = &FixedBackoff{delay: 0}
 
 // NoRetry returns a Backoff indicates that no retry.
-var NoRetry Backoff = &FixedBackoff{delayMillis: -1}
+var NoRetry Backoff = &FixedBackoff{delay: -1}
@@ -16,10 +16,11 @@ package retry
 
 import (
 	"testing"
+	"time"
 )
 
 func TestFixedBackoff(t *testing.T) {
-	if backoff, _ := NewFixedBackoff(12); backoff.delayMillis != 12 || backoff.NextDelayMillis(31) != 12 {
+	if backoff, _ := NewFixedBackoff(12); backoff.delay != 12*time.Millisecond || backoff.NextDelayMillis(31) != 12 {
 		t.FailNow()
 	}
 
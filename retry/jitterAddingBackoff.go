@@ -16,6 +16,7 @@ package retry
 
 import (
 	"fmt"
+	"time"
 )
 
 // JitterAddingBackoff returns a Backoff that adds a random jitter value to the original delay using
@@ -24,6 +25,7 @@ type JitterAddingBackoff struct {
 	minJitterRate float64
 	maxJitterRate float64
 	delegate      Backoff
+	durations     DurationBackoff // delegate as DurationBackoff
 }
 
 // NewJitterAddingBackoff creates new JitterAddingBackoff.
@@ -37,11 +39,30 @@ func NewJitterAddingBackoff(delegate Backoff, minJitterRate, maxJitterRate float
 	} else if minJitterRate > maxJitterRate {
 		err = fmt.Errorf("maxJitterRate: %.3f needs to be greater than or equal to minJitterRate: %.3f", maxJitterRate, minJitterRate)
 	} else {
-		b = &JitterAddingBackoff{minJitterRate: minJitterRate, maxJitterRate: maxJitterRate, delegate: delegate}
+		b = &JitterAddingBackoff{minJitterRate: minJitterRate, maxJitterRate: maxJitterRate, delegate: delegate, durations: AsDurationBackoff(delegate)}
 	}
 	return
 }
 
+// Fresh returns a new JitterAddingBackoff over a fresh instance of the delegate if it is a StatefulBackoff,
+// otherwise returns this backoff.
+func (f *JitterAddingBackoff) Fresh() Backoff {
+	if _, ok := f.delegate.(StatefulBackoff); !ok {
+		return f
+	}
+	delegate := Fresh(f.delegate)
+	return &JitterAddingBackoff{minJitterRate: f.minJitterRate, maxJitterRate: f.maxJitterRate, delegate: delegate, durations: AsDurationBackoff(delegate)}
+}
+
+// String returns the specification of this backoff, which is the specification of the delegate
+// followed by the jitter rates, e.g. "fixed=200,jitter=0.2" or "fixed=200,jitter=-0.1:0.3".
+func (f *JitterAddingBackoff) String() string {
+	if f.minJitterRate == -f.maxJitterRate {
+		return specOf(f.delegate) + ",jitter=" + formatFloat(f.maxJitterRate)
+	}
+	return specOf(f.delegate) + ",jitter=" + formatFloat(f.minJitterRate) + ":" + formatFloat(f.maxJitterRate)
+}
+
 // NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
 func (f *JitterAddingBackoff) NextDelayMillis(numAttemptsSoFar int) (nextDelay int64) {
 	tmp := f.delegate.NextDelayMillis(numAttemptsSoFar)
@@ -56,3 +77,18 @@ func (f *JitterAddingBackoff) NextDelayMillis(numAttemptsSoFar int) (nextDelay i
 	}
 	return
 }
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *JitterAddingBackoff) NextDelay(numAttemptsSoFar int) (nextDelay time.Duration) {
+	tmp := f.durations.NextDelay(numAttemptsSoFar)
+	if tmp <= 0 {
+		return tmp
+	}
+
+	minJitter := int64(float64(tmp) * (1 + f.minJitterRate))
+	maxJitter := int64(float64(tmp) * (1 + f.maxJitterRate))
+	if nextDelay = time.Duration(minJitter + nextRandomInt64IncludingZero(maxJitter-minJitter+1)); nextDelay < 0 {
+		nextDelay = 0
+	}
+	return
+}
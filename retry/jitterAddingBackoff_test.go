@@ -16,6 +16,7 @@ package retry
 
 import (
 	"testing"
+	"time"
 )
 
 func TestJitterAddingBackoff(t *testing.T) {
@@ -44,14 +45,14 @@ func TestJitterAddingBackoff(t *testing.T) {
 	}
 
 	// fake backoff
-	if b, err := NewJitterAddingBackoff(&FixedBackoff{delayMillis: -1}, 0.5, 0.9); err != nil || b == nil {
+	if b, err := NewJitterAddingBackoff(&FixedBackoff{delay: -time.Millisecond}, 0.5, 0.9); err != nil || b == nil {
 		t.FailNow()
 	} else if b.NextDelayMillis(2) >= 0 {
 		t.FailNow()
 	}
 
 	// real backoff
-	if b, err := NewJitterAddingBackoff(&ExponentialBackoff{initialDelayMillis: 100, maxDelayMillis: 1200, multiplier: 1.2},
+	if b, err := NewJitterAddingBackoff(&ExponentialBackoff{initialDelay: 100 * time.Millisecond, maxDelay: 1200 * time.Millisecond, multiplier: 1.2},
 		0.7, 0.97); err != nil || b == nil {
 		t.FailNow()
 	} else {
@@ -66,7 +67,7 @@ func TestJitterAddingBackoff_Stochastic(t *testing.T) {
 	initialDelay := int64(100)
 	minJitter := -0.02
 	maxJitter := 0.03
-	if b, err := NewJitterAddingBackoff(&FixedBackoff{delayMillis: initialDelay}, minJitter, maxJitter); err != nil || b == nil {
+	if b, err := NewJitterAddingBackoff(&FixedBackoff{delay: millisToDuration(initialDelay)}, minJitter, maxJitter); err != nil || b == nil {
 		t.FailNow()
 	} else {
 		histogram := make(map[int64]int)
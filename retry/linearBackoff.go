@@ -0,0 +1,88 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LinearBackoff waits for a linearly-increasing amount of time between attempts:
+// initialDelay + (numAttemptsSoFar - 1) * increment, up to maxDelay.
+type LinearBackoff struct {
+	initialDelay time.Duration
+	increment    time.Duration
+	maxDelay     time.Duration
+}
+
+// NewLinearBackoff creates new LinearBackoff.
+func NewLinearBackoff(initialDelayMillis, incrementMillis, maxDelayMillis int64) (b *LinearBackoff, err error) {
+	if initialDelayMillis < 0 {
+		err = fmt.Errorf("initialDelayMillis: %d (expected: >= 0)", initialDelayMillis)
+	} else if incrementMillis < 0 {
+		err = fmt.Errorf("incrementMillis: %d (expected: >= 0)", incrementMillis)
+	} else if initialDelayMillis > maxDelayMillis {
+		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, initialDelayMillis)
+	} else {
+		b = &LinearBackoff{
+			initialDelay: millisToDuration(initialDelayMillis),
+			increment:    millisToDuration(incrementMillis),
+			maxDelay:     millisToDuration(maxDelayMillis),
+		}
+	}
+	return
+}
+
+// NewLinearDurationBackoff creates new LinearBackoff, with delays which could be less than a millisecond.
+func NewLinearDurationBackoff(initialDelay, increment, maxDelay time.Duration) (b *LinearBackoff, err error) {
+	if initialDelay < 0 {
+		err = fmt.Errorf("initialDelay: %v (expected: >= 0)", initialDelay)
+	} else if increment < 0 {
+		err = fmt.Errorf("increment: %v (expected: >= 0)", increment)
+	} else if initialDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, initialDelay)
+	} else {
+		b = &LinearBackoff{initialDelay: initialDelay, increment: increment, maxDelay: maxDelay}
+	}
+	return
+}
+
+// String returns the specification of this backoff, e.g. "linear=200:200:10000".
+func (f *LinearBackoff) String() string {
+	return "linear=" + formatMillis(f.initialDelay) + ":" + formatMillis(f.increment) + ":" + formatMillis(f.maxDelay)
+}
+
+// NextDelayMillis returns the number of milliseconds to wait for before attempting a retry.
+func (f *LinearBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *LinearBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	if numAttemptsSoFar <= 1 {
+		return f.initialDelay
+	}
+
+	n := int64(numAttemptsSoFar - 1)
+	if f.increment > 0 && n > (math.MaxInt64-int64(f.initialDelay))/int64(f.increment) {
+		return f.maxDelay
+	}
+
+	if nextDelay := f.initialDelay + time.Duration(n)*f.increment; nextDelay < f.maxDelay {
+		return nextDelay
+	}
+	return f.maxDelay
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLinearBackoff(t *testing.T) {
+	if _, err := NewLinearBackoff(-1, 1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewLinearBackoff(1, -1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewLinearBackoff(3, 1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewLinearDurationBackoff(-1, 1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewLinearDurationBackoff(1, -1, 2); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewLinearDurationBackoff(3, 1, 2); err == nil {
+		t.FailNow()
+	}
+
+	b, err := NewLinearBackoff(100, 250, 1000)
+	if err != nil || b == nil {
+		t.FailNow()
+	}
+
+	for i, expected := range []int64{100, 350, 600, 850, 1000, 1000} {
+		if d := b.NextDelayMillis(i + 1); d != expected {
+			t.Fatal(i+1, d)
+		}
+	}
+
+	if b.NextDelayMillis(math.MaxInt32) != 1000 {
+		t.FailNow()
+	}
+
+	if b, _ = NewLinearBackoff(100, 0, 1000); b.NextDelayMillis(10) != 100 {
+		t.FailNow()
+	}
+
+	if b, _ = NewLinearBackoff(1, math.MaxInt64, math.MaxInt64); b.NextDelay(3) != math.MaxInt64 {
+		t.FailNow()
+	}
+
+	if b, _ = NewLinearDurationBackoff(100*time.Microsecond, 50*time.Microsecond, time.Millisecond); b.NextDelay(3) != 200*time.Microsecond {
+		t.FailNow()
+	}
+}
@@ -16,14 +16,15 @@ package retry
 
 import (
 	"fmt"
+	"time"
 )
 
 // RandomBackoff computes backoff delay which is a random value between
 // minDelayMillis} and maxDelayMillis.
 type RandomBackoff struct {
-	minDelayMillis int64
-	maxDelayMillis int64
-	bound          int64
+	minDelay time.Duration
+	maxDelay time.Duration
+	bound    int64
 }
 
 // NewRandomBackoff creates new RandomBackoff.
@@ -33,15 +34,41 @@ func NewRandomBackoff(minDelayMillis, maxDelayMillis int64) (b *RandomBackoff, e
 	} else if minDelayMillis > maxDelayMillis {
 		err = fmt.Errorf("maxDelayMillis: %d (expected: >= %d)", maxDelayMillis, minDelayMillis)
 	} else {
-		b = &RandomBackoff{minDelayMillis: minDelayMillis, maxDelayMillis: maxDelayMillis, bound: maxDelayMillis - minDelayMillis}
+		b = newRandomBackoff(millisToDuration(minDelayMillis), millisToDuration(maxDelayMillis))
 	}
 	return
 }
 
+// NewRandomDurationBackoff creates new RandomBackoff, with delays which could be less than a millisecond.
+func NewRandomDurationBackoff(minDelay, maxDelay time.Duration) (b *RandomBackoff, err error) {
+	if minDelay < 0 {
+		err = fmt.Errorf("minDelay: %v (expected: >= 0)", minDelay)
+	} else if minDelay > maxDelay {
+		err = fmt.Errorf("maxDelay: %v (expected: >= %v)", maxDelay, minDelay)
+	} else {
+		b = newRandomBackoff(minDelay, maxDelay)
+	}
+	return
+}
+
+func newRandomBackoff(minDelay, maxDelay time.Duration) *RandomBackoff {
+	return &RandomBackoff{minDelay: minDelay, maxDelay: maxDelay, bound: int64(maxDelay - minDelay)}
+}
+
+// String returns the specification of this backoff, e.g. "random=0:200".
+func (f *RandomBackoff) String() string {
+	return "random=" + formatMillis(f.minDelay) + ":" + formatMillis(f.maxDelay)
+}
+
 // NextDelayMillis returns number of milliseconds to wait for before attempting a retry.
 func (f *RandomBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
-	if f.minDelayMillis != f.maxDelayMillis {
-		return nextRandomInt64(f.bound) + f.minDelayMillis
+	return durationToMillis(f.NextDelay(numAttemptsSoFar))
+}
+
+// NextDelay returns the duration to wait for before attempting a retry.
+func (f *RandomBackoff) NextDelay(numAttemptsSoFar int) time.Duration {
+	if f.minDelay != f.maxDelay {
+		return time.Duration(nextRandomInt64(f.bound)) + f.minDelay
 	}
-	return f.minDelayMillis
+	return f.minDelay
 }
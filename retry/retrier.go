@@ -0,0 +1,234 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation is the operation retried by Retrier. The context of each attempt is done when
+// the context of the retry operation is done, or when the attempt times out.
+type Operation func(ctx context.Context) error
+
+// Error is returned by Retrier when it gives up, recording the error of every attempt.
+type Error struct {
+	// Errors are the errors returned by attempts, in order.
+	Errors []error
+	// Context is the error of the context of the retry operation if Retrier gave up because it was done, otherwise nil.
+	Context error
+}
+
+// Error returns the errors of all attempts.
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString("giving up after ")
+	sb.WriteString(strconv.Itoa(len(e.Errors)))
+	sb.WriteString(" attempt(s)")
+	for i, err := range e.Errors {
+		sb.WriteString(", attempt ")
+		sb.WriteString(strconv.Itoa(i + 1))
+		sb.WriteString(": ")
+		sb.WriteString(err.Error())
+	}
+	if e.Context != nil {
+		sb.WriteString(", ")
+		sb.WriteString(e.Context.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of all attempts and the context error, if any,
+// so that errors.Is and errors.As match any of them.
+func (e *Error) Unwrap() []error {
+	if e.Context == nil {
+		return e.Errors
+	}
+	return append(e.Errors[:len(e.Errors):len(e.Errors)], e.Context)
+}
+
+// Last returns the error of the last attempt.
+func (e *Error) Last() error {
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Retrier attempts an Operation until it succeeds, waiting between attempts as controlled by Backoff.
+// It gives up when the error is not retryable, when Backoff returns a negative delay
+// or when the context of the retry operation is done.
+//
+// Retrier is safe for concurrent use, as long as its hooks are. A StatefulBackoff is made Fresh for every operation.
+type Retrier struct {
+	backoff        DurationBackoff
+	retryable      func(err error) bool
+	onRetry        func(numAttemptsSoFar int, err error, delay time.Duration)
+	onGiveUp       func(err *Error)
+	attemptTimeout time.Duration
+}
+
+// RetrierBuilder is the builder for Retrier.
+type RetrierBuilder struct {
+	backoff        DurationBackoff
+	retryable      func(err error) bool
+	onRetry        func(numAttemptsSoFar int, err error, delay time.Duration)
+	onGiveUp       func(err *Error)
+	attemptTimeout time.Duration
+}
+
+// NewRetrierBuilder creates new retrier builder.
+func NewRetrierBuilder() *RetrierBuilder {
+	return &RetrierBuilder{}
+}
+
+// Backoff sets the backoff between attempts, which is mandatory. A backoff which never returns
+// a negative delay, e.g. FixedBackoff, retries until the operation succeeds or the context is done.
+func (b *RetrierBuilder) Backoff(backoff Backoff) *RetrierBuilder {
+	b.backoff = AsDurationBackoff(backoff)
+	return b
+}
+
+// DurationBackoff sets the backoff between attempts, which could be less than a millisecond. See Backoff.
+func (b *RetrierBuilder) DurationBackoff(backoff DurationBackoff) *RetrierBuilder {
+	b.backoff = backoff
+	return b
+}
+
+// Retryable sets the predicate deciding whether an attempt which returned err should be retried.
+//
+// Default: every error is retryable.
+func (b *RetrierBuilder) Retryable(retryable func(err error) bool) *RetrierBuilder {
+	b.retryable = retryable
+	return b
+}
+
+// OnRetry sets the hook invoked after a failed attempt, before waiting for delay to attempt again.
+func (b *RetrierBuilder) OnRetry(onRetry func(numAttemptsSoFar int, err error, delay time.Duration)) *RetrierBuilder {
+	b.onRetry = onRetry
+	return b
+}
+
+// OnGiveUp sets the hook invoked when Retrier gives up, with the error it returns.
+func (b *RetrierBuilder) OnGiveUp(onGiveUp func(err *Error)) *RetrierBuilder {
+	b.onGiveUp = onGiveUp
+	return b
+}
+
+// AttemptTimeout sets the timeout of each attempt. An attempt which times out fails with
+// context.DeadlineExceeded, which is subject to Retryable as other errors.
+//
+// Default: 0, attempts time out only when the context of the retry operation is done.
+func (b *RetrierBuilder) AttemptTimeout(attemptTimeout time.Duration) *RetrierBuilder {
+	b.attemptTimeout = attemptTimeout
+	return b
+}
+
+// Build the retrier.
+func (b *RetrierBuilder) Build() (r *Retrier, err error) {
+	if b.backoff == nil {
+		err = fmt.Errorf("Backoff must be not nil")
+	} else if b.attemptTimeout < 0 {
+		err = fmt.Errorf("attemptTimeout: %d (expected: >= 0)", b.attemptTimeout)
+	} else {
+		r = &Retrier{
+			backoff:        b.backoff,
+			retryable:      b.retryable,
+			onRetry:        b.onRetry,
+			onGiveUp:       b.onGiveUp,
+			attemptTimeout: b.attemptTimeout,
+		}
+	}
+	return
+}
+
+// Do attempts op until it succeeds, retrying every error with given backoff.
+// See Retrier.Do.
+func Do(ctx context.Context, backoff Backoff, op Operation) error {
+	r, err := NewRetrierBuilder().Backoff(backoff).Build()
+	if err != nil {
+		return err
+	}
+	return r.Do(ctx, op)
+}
+
+// Do attempts op until it succeeds. Returns nil on success, otherwise an *Error recording
+// the error of every attempt.
+func (r *Retrier) Do(ctx context.Context, op Operation) error {
+	// stateful backoff keeps state of this operation only
+	backoff := freshDurationBackoff(r.backoff)
+
+	var errs []error
+	for numAttemptsSoFar := 1; ; numAttemptsSoFar++ {
+		err := r.attempt(ctx, op)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return r.giveUp(&Error{Errors: errs, Context: ctxErr})
+		}
+		if r.retryable != nil && !r.retryable(err) {
+			return r.giveUp(&Error{Errors: errs})
+		}
+
+		delay := backoff.NextDelay(numAttemptsSoFar)
+		if delay < 0 {
+			return r.giveUp(&Error{Errors: errs})
+		}
+
+		if r.onRetry != nil {
+			r.onRetry(numAttemptsSoFar, err, delay)
+		}
+
+		if err = sleep(ctx, delay); err != nil {
+			return r.giveUp(&Error{Errors: errs, Context: err})
+		}
+	}
+}
+
+func (r *Retrier) attempt(ctx context.Context, op Operation) error {
+	if r.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.attemptTimeout)
+		defer cancel()
+	}
+	return op(ctx)
+}
+
+func (r *Retrier) giveUp(err *Error) error {
+	if r.onGiveUp != nil {
+		r.onGiveUp(err)
+	}
+	return err
+}
+
+// sleep waits for delay, or until ctx is done. Returns the context error in the latter case.
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errPermanent = fmt.Errorf("permanent")
+
+func TestNewRetrierBuilder(t *testing.T) {
+	if _, err := NewRetrierBuilder().Build(); err == nil {
+		t.FailNow()
+	}
+
+	if _, err := NewRetrierBuilder().Backoff(NoDelayBackoff).AttemptTimeout(-1).Build(); err == nil {
+		t.FailNow()
+	}
+
+	if err := Do(context.Background(), nil, func(context.Context) error { return nil }); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestRetrier_Success(t *testing.T) {
+	attempts := 0
+	err := Do(context.Background(), NoDelayBackoff, func(context.Context) error {
+		if attempts++; attempts < 3 {
+			return fmt.Errorf("attempt %d", attempts)
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatal(err, attempts)
+	}
+}
+
+func TestRetrier_GiveUp(t *testing.T) {
+	backoff, _ := NewAttemptLimitingBackoff(NoDelayBackoff, 3)
+
+	var retries []string
+	var givenUp *Error
+	r, err := NewRetrierBuilder().
+		Backoff(backoff).
+		OnRetry(func(numAttemptsSoFar int, err error, delay time.Duration) {
+			retries = append(retries, fmt.Sprintf("%d:%v:%v", numAttemptsSoFar, err, delay))
+		}).
+		OnGiveUp(func(err *Error) {
+			givenUp = err
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	err = r.Do(context.Background(), func(context.Context) error {
+		attempts++
+		return fmt.Errorf("attempt %d", attempts)
+	})
+
+	var retryErr *Error
+	if !errors.As(err, &retryErr) || retryErr != givenUp || len(retryErr.Errors) != 3 || retryErr.Context != nil ||
+		retryErr.Last().Error() != "attempt 3" {
+		t.Fatal(err)
+	}
+	if err.Error() != "giving up after 3 attempt(s), attempt 1: attempt 1, attempt 2: attempt 2, attempt 3: attempt 3" {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(retries) != "[1:attempt 1:0s 2:attempt 2:0s]" {
+		t.Fatal(retries)
+	}
+}
+
+func TestRetrier_Retryable(t *testing.T) {
+	r, _ := NewRetrierBuilder().
+		Backoff(NoDelayBackoff).
+		Retryable(func(err error) bool { return !errors.Is(err, errPermanent) }).
+		Build()
+
+	attempts := 0
+	err := r.Do(context.Background(), func(context.Context) error {
+		if attempts++; attempts < 2 {
+			return fmt.Errorf("transient")
+		}
+		return fmt.Errorf("wrapped: %w", errPermanent)
+	})
+	if !errors.Is(err, errPermanent) || attempts != 2 {
+		t.Fatal(err, attempts)
+	}
+
+	// no retry at all
+	attempts = 0
+	if err = Do(context.Background(), NoRetry, func(context.Context) error { attempts++; return errPermanent }); !errors.Is(err, errPermanent) || attempts != 1 {
+		t.Fatal(err, attempts)
+	}
+}
+
+func TestRetrier_AttemptTimeout(t *testing.T) {
+	backoff, _ := NewAttemptLimitingBackoff(NoDelayBackoff, 2)
+	r, _ := NewRetrierBuilder().Backoff(backoff).AttemptTimeout(time.Millisecond).Build()
+
+	attempts := 0
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		if attempts++; attempts < 2 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatal(err, attempts)
+	}
+}
+
+func TestRetrier_ContextDone(t *testing.T) {
+	backoff, _ := NewFixedBackoff(10000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Do(ctx, backoff, func(context.Context) error { return errPermanent })
+
+	var retryErr *Error
+	if !errors.As(err, &retryErr) || len(retryErr.Errors) != 1 || !errors.Is(err, context.DeadlineExceeded) ||
+		!errors.Is(err, errPermanent) || time.Since(start) > 5*time.Second {
+		t.Fatal(err)
+	}
+
+	// done context during attempt
+	ctx, cancel = context.WithCancel(context.Background())
+	err = Do(ctx, NoDelayBackoff, func(context.Context) error { cancel(); return errPermanent })
+	if !errors.As(err, &retryErr) || len(retryErr.Errors) != 1 || retryErr.Context != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestRetrier_FreshBackoff(t *testing.T) {
+	decorrelated, _ := NewDecorrelatedJitterDurationBackoff(time.Microsecond, time.Millisecond)
+	backoff, _ := NewAttemptLimitingBackoff(decorrelated, 3)
+	r, _ := NewRetrierBuilder().Backoff(backoff).Build()
+
+	err := r.Do(context.Background(), func(context.Context) error { return errPermanent })
+	var retryErr *Error
+	if !errors.As(err, &retryErr) || len(retryErr.Errors) != 3 {
+		t.Fatal(err)
+	}
+
+	// the shared backoff is untouched
+	if decorrelated.prev != int64(time.Microsecond) {
+		t.Fatal(decorrelated.prev)
+	}
+}
+
+// countingBackoff is a StatefulBackoff which does not implement DurationBackoff, delaying more at every call.
+type countingBackoff struct {
+	calls int64
+}
+
+func (c *countingBackoff) NextDelayMillis(numAttemptsSoFar int) int64 {
+	c.calls++
+	if numAttemptsSoFar >= 3 {
+		return -1
+	}
+	return c.calls - 1
+}
+
+func (c *countingBackoff) Fresh() Backoff {
+	return &countingBackoff{}
+}
+
+func TestRetrier_FreshMillisBackoff(t *testing.T) {
+	shared := &countingBackoff{}
+	var delays []time.Duration
+	r, _ := NewRetrierBuilder().
+		Backoff(shared).
+		OnRetry(func(_ int, _ error, delay time.Duration) { delays = append(delays, delay) }).
+		Build()
+
+	for i := 0; i < 2; i++ {
+		_ = r.Do(context.Background(), func(context.Context) error { return errPermanent })
+	}
+
+	// every operation starts from the initial state, and the shared backoff is untouched
+	expected := []time.Duration{0, time.Millisecond, 0, time.Millisecond}
+	if fmt.Sprint(delays) != fmt.Sprint(expected) || shared.calls != 0 {
+		t.Fatal(delays, shared.calls)
+	}
+}
@@ -15,7 +15,11 @@
 package retry
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/valyala/fastrand"
 )
@@ -71,3 +75,82 @@ func nextRandomInt64IncludingZero(bound int64) (result int64) {
 
 	return
 }
+
+// millisToDuration converts milliseconds to time.Duration, saturating on overflow.
+func millisToDuration(millis int64) time.Duration {
+	switch {
+	case millis > math.MaxInt64/int64(time.Millisecond):
+		return math.MaxInt64
+	case millis < math.MinInt64/int64(time.Millisecond):
+		return math.MinInt64
+	default:
+		return time.Duration(millis) * time.Millisecond
+	}
+}
+
+// durationToMillis converts time.Duration to milliseconds, truncating sub-millisecond. A negative duration,
+// which indicates no more retry, is converted to -1 even if it is shorter than a millisecond.
+func durationToMillis(d time.Duration) int64 {
+	if d < 0 {
+		return -1
+	}
+	return d.Milliseconds()
+}
+
+// formatMillis formats non-negative d as milliseconds for specification, with the sub-millisecond as fraction,
+// e.g. "0.5", which parseMillis parses back to d.
+func formatMillis(d time.Duration) string {
+	millis, nanos := int64(d/time.Millisecond), int64(d%time.Millisecond)
+	if nanos == 0 {
+		return strconv.FormatInt(millis, 10)
+	}
+	fraction := strconv.FormatInt(nanos+int64(time.Millisecond), 10)[1:] // zero padded to 6 digits
+	return strconv.FormatInt(millis, 10) + "." + strings.TrimRight(fraction, "0")
+}
+
+// parseMillis parses milliseconds of specification, which could have up to 6 fractional digits, i.e. nanoseconds.
+// Saturates on overflow like millisToDuration.
+func parseMillis(value string) (d time.Duration, err error) {
+	integer, fraction, hasFraction := strings.Cut(value, ".")
+
+	var millis int64
+	if millis, err = strconv.ParseInt(integer, 10, 64); err != nil {
+		return
+	}
+	d = millisToDuration(millis)
+	if !hasFraction {
+		return
+	}
+
+	if fraction == "" || len(fraction) > 6 || strings.TrimLeft(fraction, "0123456789") != "" {
+		err = ErrInvalidSpecFormat
+		return
+	}
+	nanos, _ := strconv.ParseInt(fraction+strings.Repeat("0", 6-len(fraction)), 10, 64)
+	if strings.HasPrefix(integer, "-") {
+		nanos = -nanos
+	}
+
+	switch {
+	case nanos > 0 && d > math.MaxInt64-time.Duration(nanos):
+		d = math.MaxInt64
+	case nanos < 0 && d < math.MinInt64-time.Duration(nanos):
+		d = math.MinInt64
+	default:
+		d += time.Duration(nanos)
+	}
+	return
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// specOf returns the specification of b if it is a fmt.Stringer, otherwise the type of b,
+// which BaseBackoffSpec rejects as it is not a specification.
+func specOf(b Backoff) string {
+	if s, ok := b.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", b)
+}